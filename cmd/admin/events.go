@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+
+	"gorm.io/gorm"
+)
+
+func (a *admin) runEvent(args []string) error {
+	name, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return a.listEvents(args)
+	case "cancel":
+		return a.cancelEvent(args)
+	default:
+		return fmt.Errorf("unknown event subcommand %q\n%s", name, usage)
+	}
+}
+
+func (a *admin) listEvents(args []string) error {
+	flags := flag.NewFlagSet("event list", flag.ContinueOnError)
+	email := flags.String("email", "", "only list events organized by this user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var events []event.Event
+	var err error
+	if *email != "" {
+		organizer, findErr := a.findUser(*email)
+		if findErr != nil {
+			return findErr
+		}
+		events, err = a.eventRepository.FindAllByUserID(a.ctx, organizer.ID)
+	} else {
+		events, err = a.eventRepository.FindAll(a.ctx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tDATE\tNAME\tLOCATION\tORGANIZER ID")
+	for _, existingEvent := range events {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			existingEvent.ID,
+			existingEvent.Date.Format("2006-01-02 15:04"),
+			existingEvent.Name,
+			existingEvent.Location,
+			existingEvent.UserID,
+		)
+	}
+	return writer.Flush()
+}
+
+// cancelEvent removes the event and its memberships, as when the organizer deletes it.
+func (a *admin) cancelEvent(args []string) error {
+	flags := flag.NewFlagSet("event cancel", flag.ContinueOnError)
+	eventID := flags.String("id", "", "event to cancel")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *eventID == "" {
+		return errors.New("-id is required")
+	}
+
+	existingEvent, err := a.eventRepository.FindOneByID(a.ctx, *eventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("no event with ID %s", *eventID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	members, err := a.eventMemberRepository.FindAllByEventID(a.ctx, existingEvent.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.eventRepository.Delete(a.ctx, existingEvent.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cancelled event %s (%s) and removed %d members\n", existingEvent.Name, existingEvent.ID, len(members))
+	return nil
+}
@@ -0,0 +1,126 @@
+// Command admin runs maintenance tasks against the same database and Redis as the API, so
+// that operators do not have to edit data by hand.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/user"
+	"github.com/edwinedjokpa/event-booking-api/internal/config"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/db"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/redis"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/denylist"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/session"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: admin <command> [arguments]
+
+Commands:
+  user create -email EMAIL -first-name NAME -last-name NAME [-password PASSWORD] [-role ROLE]
+  user set-role -email EMAIL -role ROLE
+  user reset-password -email EMAIL [-password PASSWORD]
+  session list -email EMAIL
+  session revoke -email EMAIL (-id SESSION_ID | -all)
+  event list [-email ORGANIZER_EMAIL]
+  event cancel -id EVENT_ID
+  migrate up | down [steps] | status
+  seed (not available in production)
+
+The first admin is made from an existing account with user set-role, or created with
+user create -role admin.
+`
+
+type admin struct {
+	ctx                   context.Context
+	production            bool
+	userRepository        user.UserRepository
+	eventRepository       event.EventRepository
+	eventMemberRepository event.EventMemberRepository
+	sessionService        *session.SessionService
+	denylistService       *denylist.DenylistService
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	config, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	gormDB, err := db.NewGormDB(config.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+
+	// Migrations only need the database, and must work before anything else does.
+	if command == "migrate" {
+		if err := db.RunMigrateCommand(context.Background(), gormDB, args, os.Stdout); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	app, err := newAdmin(config, gormDB)
+	if err != nil {
+		log.Fatalf("Error setting up: %v", err)
+	}
+
+	switch command {
+	case "user":
+		err = app.runUser(args)
+	case "session":
+		err = app.runSession(args)
+	case "event":
+		err = app.runEvent(args)
+	case "seed":
+		err = app.seed()
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("%s failed: %v", command, err)
+	}
+}
+
+func newAdmin(config *config.Config, gormDB *gorm.DB) (*admin, error) {
+	redisClient, err := redis.NewRedisClient(config.RedisAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Changes made here must reach the API's event cache too.
+	eventCache := event.NewEventCache(redisClient, slog.Default())
+
+	return &admin{
+		ctx:                   context.Background(),
+		production:            config.IsProduction(),
+		userRepository:        user.NewUserRepository(gormDB),
+		eventRepository:       event.NewCachedEventRepository(event.NewEventRepository(gormDB), eventCache),
+		eventMemberRepository: event.NewCachedEventMemberRepository(event.NewEventMemberRepository(gormDB), eventCache),
+		sessionService:        session.NewSessionService(redisClient),
+		denylistService:       denylist.NewDenylistService(redisClient),
+	}, nil
+}
+
+// subcommand splits "<name> [flags]" and reports usage when the name is missing.
+func subcommand(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("missing subcommand\n%s", usage)
+	}
+	return args[0], args[1:], nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+)
+
+// errSeedInProduction stops demo accounts, admin included, from being created in production.
+var errSeedInProduction = errors.New("seed data is not available in production")
+
+var seedUsers = []struct {
+	email     string
+	firstName string
+	lastName  string
+	role      rbac.Role
+}{
+	{"admin@example.com", "Ada", "Admin", rbac.RoleAdmin},
+	{"organizer@example.com", "Olu", "Organizer", rbac.RoleOrganizer},
+	{"attendee@example.com", "Ama", "Attendee", rbac.RoleAttendee},
+}
+
+var seedEvents = []struct {
+	name        string
+	description string
+	location    string
+	daysAhead   int
+}{
+	{"Go Meetup", "An evening of lightning talks about Go in production.", "Lagos", 14},
+	{"Design Systems Workshop", "A hands-on workshop on building and maintaining design systems.", "Accra", 30},
+	{"Cloud Summit", "A one-day conference on running services in the cloud.", "Nairobi", 60},
+}
+
+// seed creates demo users with generated passwords, and demo events. It skips users that
+// already exist, and only adds events when the demo organizer has none, so it can be run
+// repeatedly.
+func (a *admin) seed() error {
+	if a.production {
+		return errSeedInProduction
+	}
+
+	var organizerID string
+
+	for _, seedUser := range seedUsers {
+		existingUser, err := a.userRepository.FindOneByEmail(a.ctx, seedUser.email)
+		if err == nil {
+			fmt.Printf("User %s already exists\n", seedUser.email)
+			if seedUser.role == rbac.RoleOrganizer {
+				organizerID = existingUser.ID
+			}
+			continue
+		}
+
+		newUser, generatedPassword, err := a.createUserRecord(seedUser.email, seedUser.firstName, seedUser.lastName, "", seedUser.role)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created %s user %s with password %s\n", newUser.Role, newUser.Email, generatedPassword)
+		if seedUser.role == rbac.RoleOrganizer {
+			organizerID = newUser.ID
+		}
+	}
+
+	existingEvents, err := a.eventRepository.FindAllByUserID(a.ctx, organizerID)
+	if err != nil {
+		return err
+	}
+
+	if len(existingEvents) > 0 {
+		fmt.Println("Demo events already exist")
+		return nil
+	}
+
+	now := time.Now()
+	for _, seedEvent := range seedEvents {
+		newEvent := event.Event{
+			ID:          util.GenerateUUID(),
+			Name:        seedEvent.name,
+			Description: seedEvent.description,
+			Location:    seedEvent.location,
+			Date:        now.AddDate(0, 0, seedEvent.daysAhead).Truncate(time.Hour),
+			UserID:      organizerID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		if err := a.eventRepository.Create(a.ctx, newEvent); err != nil {
+			return err
+		}
+
+		fmt.Printf("Created event %s\n", newEvent.Name)
+	}
+
+	return nil
+}
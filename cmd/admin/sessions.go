@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func (a *admin) runSession(args []string) error {
+	name, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return a.listSessions(args)
+	case "revoke":
+		return a.revokeSessions(args)
+	default:
+		return fmt.Errorf("unknown session subcommand %q\n%s", name, usage)
+	}
+}
+
+func (a *admin) listSessions(args []string) error {
+	flags := flag.NewFlagSet("session list", flag.ContinueOnError)
+	email := flags.String("email", "", "email address")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	existingUser, err := a.findUser(*email)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := a.sessionService.ListUserSessions(a.ctx, existingUser.ID)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tCREATED AT\tLAST USED AT\tIP ADDRESS\tUSER AGENT")
+	for _, sessionData := range sessions {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			sessionData.ID,
+			sessionData.CreatedAt.Format("2006-01-02 15:04"),
+			sessionData.LastUsedAt.Format("2006-01-02 15:04"),
+			sessionData.IPAddress,
+			sessionData.UserAgent,
+		)
+	}
+	return writer.Flush()
+}
+
+func (a *admin) revokeSessions(args []string) error {
+	flags := flag.NewFlagSet("session revoke", flag.ContinueOnError)
+	email := flags.String("email", "", "email address")
+	sessionID := flags.String("id", "", "session to revoke")
+	all := flags.Bool("all", false, "revoke every session of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if (*sessionID == "") == !*all {
+		return errors.New("exactly one of -id and -all is required")
+	}
+
+	existingUser, err := a.findUser(*email)
+	if err != nil {
+		return err
+	}
+
+	if *all {
+		revoked, err := a.revokeAllSessions(existingUser.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Revoked %d sessions of %s\n", revoked, existingUser.Email)
+		return nil
+	}
+
+	found, err := a.sessionService.DeleteUserSession(a.ctx, existingUser.ID, *sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%s has no session %s", existingUser.Email, *sessionID)
+	}
+
+	if err := a.denylistService.RevokeSession(a.ctx, *sessionID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked session %s of %s\n", *sessionID, existingUser.Email)
+	return nil
+}
+
+// revokeAllSessions signs the user out everywhere and returns how many sessions were ended.
+func (a *admin) revokeAllSessions(userID string) (int, error) {
+	sessionIDs, err := a.sessionService.DeleteAllUserSessions(a.ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := a.denylistService.RevokeSession(a.ctx, sessionID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(sessionIDs), nil
+}
@@ -1,83 +1,392 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/edwinedjokpa/event-booking-api/internal/app/apikey"
 	"github.com/edwinedjokpa/event-booking-api/internal/app/auth"
 	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/health"
 	"github.com/edwinedjokpa/event-booking-api/internal/app/user"
 	"github.com/edwinedjokpa/event-booking-api/internal/config"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/buildinfo"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/db"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/metrics"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/redis"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/denylist"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/export"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/idempotency"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/oidc"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/otp"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/ratelimit"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/session"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/tracing"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/validator"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/gorm"
 )
 
-// SetupApp initializes all application components and returns a configured Gin router.
-func SetupApp(config *config.Config) (*gin.Engine, error) {
+// App is the configured router together with the resources that must be released when
+// the server stops.
+type App struct {
+	Router *gin.Engine
+
+	gormDB        *gorm.DB
+	redisClient   *goredis.Client
+	exportService *export.ExportService
+	healthService *health.HealthService
+
+	shutdownTracing func(context.Context) error
+}
+
+// BeginShutdown marks the app as not ready. The server keeps serving until it is shut down.
+func (app *App) BeginShutdown() {
+	if app.healthService != nil {
+		app.healthService.BeginShutdown()
+	}
+}
+
+// Shutdown stops background workers and then closes the connections they depend on.
+// It must only be called once the HTTP server has stopped accepting requests.
+func (app *App) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	if app.exportService != nil {
+		if err := app.exportService.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping data export worker: %w", err))
+		}
+	}
+
+	// Flush spans only after the worker has stopped, so its last jobs are exported too.
+	if app.shutdownTracing != nil {
+		if err := app.shutdownTracing(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("flushing traces: %w", err))
+		}
+	}
+
+	if app.redisClient != nil {
+		if err := app.redisClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing Redis client: %w", err))
+		}
+	}
+
+	if app.gormDB != nil {
+		if sqlDB, err := app.gormDB.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing database pool: %w", err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetupApp initializes all application components and returns the configured app.
+// Anything already opened is released again if setup fails part way.
+func SetupApp(config *config.Config, logger *slog.Logger) (_ *App, err error) {
+	app := &App{}
+	defer func() {
+		if err != nil {
+			if shutdownErr := app.Shutdown(context.Background()); shutdownErr != nil {
+				logger.Error("Error releasing resources after failed setup", slog.Any("error", shutdownErr))
+			}
+		}
+	}()
+
+	// Set up tracing first so that every component below is instrumented.
+	app.shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    config.TracingExporter,
+		File:        config.TracingFile,
+		SampleRatio: config.TracingSampleRatio,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Connect to Database
 	gormDB, err := db.NewGormDB(config.DatabaseURL)
 	if err != nil {
 		return nil, err
 	}
+	app.gormDB = gormDB
 
-	// Run Database Migrations
-	db.RunMigrations(gormDB)
+	if err := gormDB.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
+	if err := gormDB.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
+	// Refuse to serve against a schema this build does not expect. Migrations are applied
+	// separately with the migrate subcommand.
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingMigrations, err := migrator.Pending(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pendingMigrations) > 0 {
+		return nil, fmt.Errorf("database has %d pending migrations, run the migrate up subcommand first", len(pendingMigrations))
+	}
+
+	// Load the token signing keys
+	signingKeys, err := util.LoadSigningKeys(config.JWTSigningKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet, err := util.NewKeySet(signingKeys, config.JWTSecret, config.JWTKeyGracePeriod)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize a single, configured validator instance.
-	appValidator := validator.NewValidator()
+	appValidator, err := validator.NewValidator()
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize the Redis Client
 	redisClient, err := redis.NewRedisClient(config.RedisAddr)
 	if err != nil {
 		return nil, err
 	}
+	app.redisClient = redisClient
+	logger.Info("Connected to Redis", slog.String("addr", config.RedisAddr))
+
+	if err := metrics.InstrumentRedis(redisClient); err != nil {
+		return nil, err
+	}
+
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		return nil, err
+	}
 
 	// Initialize the Session Service
 	sessionService := session.NewSessionService(redisClient)
 
+	// Initialize the Token Denylist Service
+	denylistService := denylist.NewDenylistService(redisClient)
+
+	// Initialize the Idempotency Service
+	idempotencyService := idempotency.NewIdempotencyService(redisClient)
+
+	// Initialize the Rate Limit Service
+	rateLimitService := ratelimit.NewRateLimitService(redisClient)
+
 	// Initialize the Otp Service
 	otpService := otp.NewOTPService(redisClient)
 
+	// Initialize the Data Export Service
+	exportService, err := export.NewExportService(redisClient, config.DataExportDir, logger)
+	if err != nil {
+		return nil, err
+	}
+	app.exportService = exportService
+
+	// Initialize the OpenID Connect Service
+	oidcProviders := make([]oidc.ProviderConfig, 0, len(config.OIDCProviders))
+	for _, provider := range config.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.ProviderConfig(provider))
+	}
+	oidcService := oidc.NewOIDCService(redisClient, oidcProviders)
+
+	// Initialize the Health Service
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	healthService := health.NewHealthService(
+		health.Check{Name: "postgres", Check: sqlDB.PingContext},
+		health.Check{Name: "redis", Check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}},
+	)
+	app.healthService = healthService
+
 	// Initialize Repositories
+	// Event reads go through Redis; both event repositories invalidate what they write.
+	eventCache := event.NewEventCache(redisClient, logger)
 	userRepository := user.NewUserRepository(gormDB)
-	eventRepository := event.NewEventRepository(gormDB)
+	uncachedEventRepository := event.NewEventRepository(gormDB)
+	eventRepository := event.NewCachedEventRepository(uncachedEventRepository, eventCache)
+	eventMemberRepository := event.NewCachedEventMemberRepository(event.NewEventMemberRepository(gormDB), eventCache)
+	apiKeyRepository := apikey.NewAPIKeyRepository(gormDB)
 
 	// Initialize Services
-	authService := auth.NewAuthService(userRepository, config.JWTSecret, sessionService, otpService)
-	eventService := event.NewEventService(eventRepository)
+	authService := auth.NewAuthService(userRepository, keySet, sessionService, otpService, denylistService, oidcService, auth.Options{
+		PasswordlessSignup:  config.PasswordlessSignupEnabled,
+		PasswordlessLinkURL: config.PasswordlessLinkURL,
+	}, logger)
+	eventService := event.NewEventService(eventRepository, uncachedEventRepository, eventMemberRepository, logger)
+	userService := user.NewUserService(userRepository, eventRepository, eventMemberRepository, sessionService, denylistService, otpService, exportService, logger)
+
+	apiKeyService := apikey.NewAPIKeyService(apiKeyRepository, userRepository)
+
+	// Start Background Workers
+	exportService.Start(userService.CollectUserData)
 
 	// Initialize Controllers
-	authController := auth.NewAuthController(authService, appValidator)
+	authController := auth.NewAuthController(authService, appValidator, auth.CookieOptions{
+		Secure:     config.CookieSecure,
+		PathPrefix: "/api",
+	})
 	eventController := event.NewEventController(eventService, appValidator)
+	userController := user.NewUserController(userService, appValidator)
+	apiKeyController := apikey.NewAPIKeyController(apiKeyService, appValidator)
+	healthController := health.NewHealthController(healthService)
 
 	// Create a new Gin router with custom middleware stack.
 	router := gin.New()
 
 	if err := router.SetTrustedProxies(nil); err != nil {
-		log.Fatalf("failed to set trusted proxies: %v", err)
+		return nil, fmt.Errorf("failed to set trusted proxies: %w", err)
 	}
 
+	// Let handlers pass the gin context on, so queries and Redis commands join the request's trace.
+	router.ContextWithFallback = true
+
 	// Register global middleware
-	router.Use(gin.Logger())
+	router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(isTraced)))
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LoggerMiddleware(logger))
+	router.Use(middleware.LocaleMiddleware())
+	router.Use(metrics.GinMiddleware())
 	router.Use(cors.Default())
 	router.Use(middleware.RecoveryMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+
+	authMiddleware := middleware.AuthMiddleware(keySet, denylistService, apiKeyService.Authenticate)
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(idempotencyService)
+	// Routes that email a code are also limited per address by the OTP service.
+	otpRateLimitMiddleware := middleware.RateLimitMiddleware(rateLimitService, "otp_send", otpSendLimit, time.Hour)
+
+	// The document is checked against the router once every route is registered.
+	document := newOpenAPIDocument()
+	documentHandler, err := document.Handler()
+	if err != nil {
+		return nil, err
+	}
+
+	registerRoutes(router, routeHandlers{
+		auth:           authController,
+		event:          eventController,
+		user:           userController,
+		apiKey:         apiKeyController,
+		health:         healthController,
+		metrics:        metrics.Handler(config.MetricsToken),
+		document:       documentHandler,
+		authMiddleware: authMiddleware,
+		idempotency:    idempotencyMiddleware,
+		otpRateLimit:   otpRateLimitMiddleware,
+	})
+
+	if err := document.Verify(router.Routes()); err != nil {
+		return nil, err
+	}
+
+	app.Router = router
+	return app, nil
+}
+
+const apiTitle = "Event Booking API"
+
+// otpSendLimit is how many requests that email a code each client IP may make per hour.
+const otpSendLimit = 20
+
+// routeHandlers holds the controllers and middleware registerRoutes wires up.
+type routeHandlers struct {
+	auth           auth.AuthController
+	event          event.EventController
+	user           user.UserController
+	apiKey         apikey.APIKeyController
+	health         health.HealthController
+	metrics        gin.HandlerFunc
+	document       gin.HandlerFunc
+	authMiddleware gin.HandlerFunc
+	idempotency    gin.HandlerFunc
+	otpRateLimit   gin.HandlerFunc
+}
+
+// registerRoutes registers every route newOpenAPIDocument describes.
+func registerRoutes(router *gin.Engine, handlers routeHandlers) {
+	health.RegisterRoutes(&router.RouterGroup, handlers.health)
+	router.GET("/metrics", handlers.metrics)
+	auth.RegisterWellKnownRoutes(&router.RouterGroup, handlers.auth)
 
 	api := router.Group("/api")
 
 	api.GET("/", func(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "Welcome to the Event Booking API")
 	})
+	api.GET("/openapi.json", handlers.document)
+	api.GET("/docs", openapi.DocsHandler(apiTitle, "/api/openapi.json"))
 
 	// Register Router
-	auth.RegisterRoutes(api, authController)
-	event.RegisterRoutes(api, eventController, []byte(config.JWTSecret))
+	auth.RegisterRoutes(api, handlers.auth, handlers.authMiddleware, handlers.otpRateLimit)
+	event.RegisterRoutes(api, handlers.event, handlers.authMiddleware, handlers.idempotency)
+	user.RegisterRoutes(api, handlers.user, handlers.authMiddleware)
+	apikey.RegisterRoutes(api, handlers.apiKey, handlers.authMiddleware)
+}
+
+// newOpenAPIDocument describes every route SetupApp registers.
+func newOpenAPIDocument() *openapi.Document {
+	document := openapi.New(apiTitle, buildinfo.Get().Version)
+	document.Add("", "Health", health.OpenAPIOperations())
+	document.Add("", "Operations", operationsOpenAPIOperations())
+	document.Add("", "Auth", auth.WellKnownOpenAPIOperations())
+	document.Add("/api", "Docs", docsOpenAPIOperations())
+	document.Add("/api", "Auth", auth.OpenAPIOperations())
+	document.Add("/api", "Events", event.OpenAPIOperations())
+	document.Add("/api", "Users", user.OpenAPIOperations())
+	document.Add("/api", "API keys", apikey.OpenAPIOperations())
+	return document
+}
+
+// operationsOpenAPIOperations documents the routes registered on the root router.
+func operationsOpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method: http.MethodGet, Path: "/metrics", Summary: "Prometheus metrics",
+			Description: "Requires \"Authorization: Bearer <METRICS_TOKEN>\" when METRICS_TOKEN is set.",
+			ContentType: "text/plain",
+		},
+	}
+}
+
+// docsOpenAPIOperations documents the routes registered on the /api group itself.
+func docsOpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/", Summary: "Welcome message", ContentType: "text/plain"},
+		{Method: http.MethodGet, Path: "/openapi.json", Summary: "This OpenAPI document", ContentType: "application/json"},
+		{Method: http.MethodGet, Path: "/docs", Summary: "Interactive API documentation", ContentType: "text/html"},
+	}
+}
 
-	return router, nil
+// isTraced leaves probes and metric scrapes out of traces, as they would drown out real requests.
+func isTraced(request *http.Request) bool {
+	switch request.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
 }
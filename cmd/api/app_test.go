@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/app/apikey"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/auth"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/health"
+	"github.com/edwinedjokpa/event-booking-api/internal/app/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestOpenAPIDocumentMatchesRoutes catches routes added or removed without updating the
+// document. Handlers are never called, so the controllers need no services.
+func TestOpenAPIDocumentMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	document := newOpenAPIDocument()
+	documentHandler, err := document.Handler()
+	if err != nil {
+		t.Fatalf("building the document handler: %v", err)
+	}
+
+	noop := func(ctx *gin.Context) { ctx.Next() }
+
+	router := gin.New()
+	registerRoutes(router, routeHandlers{
+		auth:           auth.NewAuthController(nil, nil, auth.CookieOptions{}),
+		event:          event.NewEventController(nil, nil),
+		user:           user.NewUserController(nil, nil),
+		apiKey:         apikey.NewAPIKeyController(nil, nil),
+		health:         health.NewHealthController(nil),
+		metrics:        noop,
+		document:       documentHandler,
+		authMiddleware: noop,
+		idempotency:    noop,
+		otpRateLimit:   noop,
+	})
+
+	if err := document.Verify(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,9 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/edwinedjokpa/event-booking-api/internal/config"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/db"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
 )
 
 func main() {
@@ -13,14 +23,86 @@ func main() {
 		log.Fatalf("Error loading configuration: %v", err)
 	}
 
-	// Call the setup function to get the configured router
-	router, err := SetupApp(config)
+	logger := logging.New(os.Stderr, logging.Options{
+		Format: config.LogFormat,
+		Level:  config.LogLevel,
+		Redact: config.LogRedact,
+	})
+	// Route the standard library logger, and so third-party packages, through it as well.
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		// The schema is managed separately from serving, so only the database is needed.
+		gormDB, err := db.NewGormDB(config.DatabaseURL)
+		if err == nil {
+			err = db.RunMigrateCommand(context.Background(), gormDB, os.Args[2:], os.Stdout)
+		}
+		if err != nil {
+			exit(logger, "Migration failed", err)
+		}
+		return
+	}
+
+	// Call the setup function to get the configured app
+	app, err := SetupApp(config, logger)
 	if err != nil {
-		log.Fatalf("Error setting up application: %v", err)
+		exit(logger, "Error setting up application", err)
+	}
+
+	server := &http.Server{
+		Addr:              ":" + config.Port,
+		Handler:           app.Router,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		// Long enough for data-export archives to download over slow connections.
+		WriteTimeout: 2 * time.Minute,
+		IdleTimeout:  2 * time.Minute,
 	}
 
-	// Start the server
-	if err := router.Run(":" + config.Port); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server listening", slog.String("addr", server.Addr))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if shutdownErr := app.Shutdown(context.Background()); shutdownErr != nil {
+			logger.Error("Error during shutdown", slog.Any("error", shutdownErr))
+		}
+		exit(logger, "Failed to run server", err)
+	case <-signalCtx.Done():
 	}
+
+	// Restore default signal handling so that a second signal terminates immediately.
+	stop()
+
+	logger.Info("Shutdown signal received, draining", slog.Duration("drain_period", config.ShutdownDrainPeriod))
+	app.BeginShutdown()
+	time.Sleep(config.ShutdownDrainPeriod)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error shutting down HTTP server", slog.Any("error", err))
+	}
+
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Server stopped with error", slog.Any("error", err))
+	}
+
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error during shutdown", slog.Any("error", err))
+	}
+
+	logger.Info("Server stopped")
+}
+
+func exit(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
@@ -0,0 +1,45 @@
+// Command mockoidc is a minimal OpenID Connect provider for exercising social login locally.
+// It approves every authorization request immediately, signing in as MOCK_OIDC_EMAIL or as
+// the login_hint passed on the authorization URL.
+//
+// Point the API at it with, for example:
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9100
+//	OIDC_MOCK_CLIENT_ID=event-booking-api
+//	OIDC_MOCK_CLIENT_SECRET=secret
+//	OIDC_MOCK_REDIRECT_URL=http://localhost:9000/api/auth/oidc/mock/callback
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/mockoidc"
+)
+
+func main() {
+	addr := getenv("MOCK_OIDC_ADDR", ":9100")
+	issuer := getenv("MOCK_OIDC_ISSUER", "http://localhost:9100")
+
+	provider, err := mockoidc.New(mockoidc.Options{
+		Issuer:       issuer,
+		ClientID:     getenv("MOCK_OIDC_CLIENT_ID", "event-booking-api"),
+		ClientSecret: getenv("MOCK_OIDC_CLIENT_SECRET", "secret"),
+		Email:        getenv("MOCK_OIDC_EMAIL", "jane.doe@example.com"),
+	})
+	if err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
+	}
+
+	log.Printf("Mock OIDC provider listening on %s (issuer %s)", addr, issuer)
+	log.Fatal(http.ListenAndServe(addr, provider))
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
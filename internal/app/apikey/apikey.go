@@ -0,0 +1,23 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+)
+
+type APIKey struct {
+	ID         string            `gorm:"primaryKey;not null" json:"id"`
+	UserID     string            `gorm:"not null;index" json:"user_id"`
+	Name       string            `gorm:"not null" json:"name"`
+	Prefix     string            `gorm:"not null;uniqueIndex" json:"prefix"`
+	SecretHash string            `gorm:"not null" json:"-"`
+	Scopes     []rbac.Permission `gorm:"type:text;not null;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time        `gorm:"default:NULL" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time        `gorm:"default:NULL" json:"last_used_at,omitempty"`
+	CreatedAt  time.Time         `gorm:"not null" json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"net/http"
+
+	APIKeyDTO "github.com/edwinedjokpa/event-booking-api/internal/app/apikey/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	APIResponse "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/apiresponse"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(c *gin.Context)
+	GetAPIKeys(c *gin.Context)
+	DeleteAPIKey(c *gin.Context)
+}
+
+type apiKeyController struct {
+	service   APIKeyService
+	validator *validator.Validator
+}
+
+func NewAPIKeyController(service APIKeyService, validator *validator.Validator) APIKeyController {
+	return &apiKeyController{service, validator}
+}
+
+func (ctrl *apiKeyController) CreateAPIKey(ctx *gin.Context) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	var request APIKeyDTO.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	apiKey, err := ctrl.service.CreateAPIKey(ctx, principal.UserID, request)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, APIResponse.Success(i18n.T(ctx, "apikey.created"), gin.H{"api_key": apiKey}))
+}
+
+func (ctrl *apiKeyController) GetAPIKeys(ctx *gin.Context) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	apiKeys, err := ctrl.service.GetAPIKeys(ctx, principal.UserID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "apikey.list_retrieved"), gin.H{"api_keys": apiKeys}))
+}
+
+func (ctrl *apiKeyController) DeleteAPIKey(ctx *gin.Context) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.DeleteAPIKey(ctx, principal.UserID, ctx.Param("id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "apikey.revoked"), nil))
+}
@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"net/http"
+
+	APIKeyDTO "github.com/edwinedjokpa/event-booking-api/internal/app/apikey/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
+)
+
+// OpenAPIOperations documents the routes registered by RegisterRoutes.
+func OpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/users/me/api-keys", Summary: "List the caller's API keys", Security: openapi.SecuritySession, Data: openapi.Data{"api_keys": []APIKey{}}},
+		{Method: http.MethodPost, Path: "/users/me/api-keys", Summary: "Create an API key", Security: openapi.SecuritySession, Body: APIKeyDTO.CreateAPIKeyRequest{}, Status: http.StatusCreated, Data: openapi.Data{"api_key": APIKeyDTO.CreateAPIKeyResponse{}}},
+		{Method: http.MethodDelete, Path: "/users/me/api-keys/:id", Summary: "Revoke an API key", Security: openapi.SecuritySession},
+	}
+}
@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey APIKey) error
+	FindOneByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	FindAllByUserID(ctx context.Context, userID string) ([]APIKey, error)
+	UpdateLastUsedAt(ctx context.Context, apiKeyID string, lastUsedAt time.Time) error
+	Delete(ctx context.Context, userID, apiKeyID string) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (repo *apiKeyRepository) Create(ctx context.Context, apiKey APIKey) error {
+	if err := repo.db.WithContext(ctx).Create(&apiKey).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *apiKeyRepository) FindOneByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	var apiKey APIKey
+	if err := repo.db.WithContext(ctx).First(&apiKey, "prefix = ?", prefix).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (repo *apiKeyRepository) FindAllByUserID(ctx context.Context, userID string) ([]APIKey, error) {
+	var apiKeys []APIKey
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (repo *apiKeyRepository) UpdateLastUsedAt(ctx context.Context, apiKeyID string, lastUsedAt time.Time) error {
+	return repo.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", apiKeyID).Update("last_used_at", lastUsedAt).Error
+}
+
+func (repo *apiKeyRepository) Delete(ctx context.Context, userID, apiKeyID string) error {
+	result := repo.db.WithContext(ctx).Where("id = ? AND user_id = ?", apiKeyID, userID).Delete(&APIKey{})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
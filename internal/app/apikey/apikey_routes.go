@@ -0,0 +1,18 @@
+package apikey
+
+import (
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, controller APIKeyController, authMiddleware gin.HandlerFunc) {
+	// Keys are managed only from a signed-in session, so a leaked key cannot mint more keys.
+	apiKeyRouter := router.Group("/users/me/api-keys")
+	apiKeyRouter.Use(authMiddleware, middleware.RequireSession())
+	{
+		apiKeyRouter.GET("", controller.GetAPIKeys)
+		apiKeyRouter.POST("", controller.CreateAPIKey)
+		apiKeyRouter.DELETE("/:id", controller.DeleteAPIKey)
+	}
+}
@@ -1,15 +1,20 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"net/url"
+	"strings"
 
 	AuthDTO "github.com/edwinedjokpa/event-booking-api/internal/app/auth/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/oidc"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/session"
 	APIResponse "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/apiresponse"
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
-	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/validator"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type AuthController interface {
@@ -18,145 +23,356 @@ type AuthController interface {
 	ForgotPassword(c *gin.Context)
 	ResetPassword(c *gin.Context)
 	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
 	RefreshToken(c *gin.Context)
+	GetSessions(c *gin.Context)
+	RevokeSession(c *gin.Context)
+	JWKS(c *gin.Context)
+	StartPasswordlessLogin(c *gin.Context)
+	PasswordlessLogin(c *gin.Context)
+	OIDCLogin(c *gin.Context)
+	OIDCCallback(c *gin.Context)
+}
+
+// CookieOptions controls the cookies the auth routes set.
+type CookieOptions struct {
+	// Secure restricts the cookies to HTTPS. It should be set whenever the API is served
+	// over TLS, which is always the case in production.
+	Secure bool
+	// PathPrefix is where the auth routes are mounted, such as "/api". Cookie paths are
+	// built from it so browsers send each cookie only to the route that reads it.
+	PathPrefix string
 }
 
 type authController struct {
 	service   AuthService
-	validator *validator.Validate
+	validator *validator.Validator
+	cookies   CookieOptions
 }
 
-func NewAuthController(service AuthService, validator *validator.Validate) AuthController {
-	return &authController{service, validator}
+func NewAuthController(service AuthService, validator *validator.Validator, cookies CookieOptions) AuthController {
+	return &authController{service, validator, cookies}
 }
 
 func (ctrl *authController) Register(ctx *gin.Context) {
 	var request AuthDTO.RegisterUserRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.service.Register(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.Register(request)
-	ctx.JSON(http.StatusCreated, APIResponse.Success("User account created successfully", nil))
+	ctx.JSON(http.StatusCreated, APIResponse.Success(i18n.T(ctx, "auth.registered"), nil))
 }
 
 func (ctrl *authController) Login(ctx *gin.Context) {
 	var request AuthDTO.LoginUserRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	tokens := ctrl.service.Login(ctx, request)
-	cookie := &http.Cookie{
-		Name:     "refresh_token",
-		Value:    tokens.RefreshToken,
-		Path:     "/auth/refresh",
-		Domain:   "",
-		MaxAge:   60 * 60 * 24 * 7,
-		Secure:   false,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
+	tokens, err := ctrl.service.Login(ctx, request, clientInfo(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
-	http.SetCookie(ctx.Writer, cookie)
-	ctx.JSON(http.StatusOK, APIResponse.Success("User login successfully", gin.H{"token": tokens.AccessToken}))
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie(tokens.RefreshToken, refreshTokenMaxAge))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_in"), gin.H{"token": tokens.AccessToken}))
 }
 
 func (ctrl *authController) ForgotPassword(ctx *gin.Context) {
 	var request AuthDTO.ForgotPasswordRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.service.ForgotPassword(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.ForgotPassword(request)
-	ctx.JSON(http.StatusOK, APIResponse.Success("If an account with this email exists, a password reset OTP has been sent.", nil))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.password_reset_requested"), nil))
 }
 
 func (ctrl *authController) ResetPassword(ctx *gin.Context) {
 	var request AuthDTO.ResetPasswordRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.service.ResetPassword(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.ResetPassword(request)
-	ctx.JSON(http.StatusOK, APIResponse.Success("Password reset was successful", nil))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.password_reset"), nil))
 }
 
 func (ctrl *authController) RefreshToken(ctx *gin.Context) {
 	refreshToken, err := ctx.Cookie("refresh_token")
 	if err != nil {
-		exception := HTTPException.NewUnauthorizedException("Refresh token is missing", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ErrRefreshTokenMissing)
 		return
 	}
 
-	tokens := ctrl.service.RefreshToken(ctx, refreshToken)
-	cookie := &http.Cookie{
-		Name:     "refresh_token",
-		Value:    tokens.RefreshToken,
-		Path:     "/auth/refresh",
-		Domain:   "",
-		MaxAge:   60 * 60 * 24 * 7,
-		Secure:   false,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
+	tokens, err := ctrl.service.RefreshToken(ctx, refreshToken, clientInfo(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
-	http.SetCookie(ctx.Writer, cookie)
-	ctx.JSON(http.StatusOK, APIResponse.Success("Tokens refreshed successfully", gin.H{"token": tokens.AccessToken}))
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie(tokens.RefreshToken, refreshTokenMaxAge))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.tokens_refreshed"), gin.H{"token": tokens.AccessToken}))
 }
 
 func (ctrl *authController) Logout(ctx *gin.Context) {
-	refreshToken, err := ctx.Cookie("refresh_token")
+	refreshToken, _ := ctx.Cookie("refresh_token")
+	accessToken := bearerToken(ctx)
+	if refreshToken == "" && accessToken == "" {
+		ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_out"), nil))
+		return
+	}
+
+	if err := ctrl.service.Logout(ctx, refreshToken, accessToken); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie("", -1))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_out"), nil))
+}
+
+func (ctrl *authController) LogoutAll(ctx *gin.Context) {
+	userIDRaw, exists := ctx.Get("userID")
+	if !exists {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	userID, ok := userIDRaw.(string)
+	if !ok {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.LogoutAll(ctx, userID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie("", -1))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_out_all"), nil))
+}
+
+func (ctrl *authController) GetSessions(ctx *gin.Context) {
+	userIDRaw, exists := ctx.Get("userID")
+	if !exists {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	userID, ok := userIDRaw.(string)
+	if !ok {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	sessions, err := ctrl.service.GetSessions(ctx, userID, ctx.GetString("sessionID"))
 	if err != nil {
-		ctx.JSON(http.StatusOK, APIResponse.Success("User logged out successfully", nil))
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.sessions_retrieved"), gin.H{"sessions": sessions}))
+}
+
+func (ctrl *authController) RevokeSession(ctx *gin.Context) {
+	sessionID := ctx.Param("id")
+
+	userIDRaw, exists := ctx.Get("userID")
+	if !exists {
+		ctx.Error(middleware.ErrUnauthenticated)
 		return
 	}
 
-	ctrl.service.Logout(ctx, refreshToken)
-	cookie := &http.Cookie{
+	userID, ok := userIDRaw.(string)
+	if !ok {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.RevokeSession(ctx, userID, sessionID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.session_revoked"), nil))
+}
+
+// JWKS publishes the public signing keys in the standard JWK Set format rather than
+// the API envelope, so that off-the-shelf JWT libraries can consume it directly.
+func (ctrl *authController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, ctrl.service.GetJWKS())
+}
+
+func (ctrl *authController) StartPasswordlessLogin(ctx *gin.Context) {
+	var request AuthDTO.PasswordlessLoginRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := ctrl.service.StartPasswordlessLogin(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.sign_in_code_sent"), nil))
+}
+
+func (ctrl *authController) PasswordlessLogin(ctx *gin.Context) {
+	var request AuthDTO.PasswordlessVerifyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	tokens, err := ctrl.service.PasswordlessLogin(ctx, request, clientInfo(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie(tokens.RefreshToken, refreshTokenMaxAge))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_in"), gin.H{"token": tokens.AccessToken}))
+}
+
+// oidcStateCookie holds the state of the login started in this browser. The callback only
+// accepts the state it holds, so a login started elsewhere, such as an attacker's own login
+// completed in the victim's browser, is rejected.
+const oidcStateCookie = "oidc_state"
+
+// OIDCLogin redirects the browser to the identity provider's consent screen.
+func (ctrl *authController) OIDCLogin(ctx *gin.Context) {
+	authURL, state, err := ctrl.service.OIDCAuthURL(ctx, ctx.Param("provider"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, ctrl.oidcStateCookieFor(ctx.Param("provider"), state, int(oidc.StateExpiresAt.Seconds())))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// oidcStateCookieFor scopes the state cookie to the provider's login and callback routes.
+func (ctrl *authController) oidcStateCookieFor(provider, state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     ctrl.cookies.PathPrefix + "/auth/oidc/" + url.PathEscape(provider) + "/",
+		MaxAge:   maxAge,
+		Secure:   ctrl.cookies.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (ctrl *authController) OIDCCallback(ctx *gin.Context) {
+	if providerError := ctx.Query("error"); providerError != "" {
+		ctx.Error(ErrProviderLoginIncomplete.WithDetails(providerError))
+		return
+	}
+
+	state, code := ctx.Query("state"), ctx.Query("code")
+	if state == "" || code == "" {
+		ctx.Error(ErrProviderCallbackInvalid)
+		return
+	}
+
+	// The state is single use either way, so the cookie is cleared before checking it.
+	browserState, _ := ctx.Cookie(oidcStateCookie)
+	http.SetCookie(ctx.Writer, ctrl.oidcStateCookieFor(ctx.Param("provider"), "", -1))
+	if subtle.ConstantTimeCompare([]byte(browserState), []byte(state)) != 1 {
+		ctx.Error(oidc.ErrInvalidState)
+		return
+	}
+
+	tokens, err := ctrl.service.OIDCLogin(ctx, ctx.Param("provider"), state, code, clientInfo(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	http.SetCookie(ctx.Writer, ctrl.refreshTokenCookie(tokens.RefreshToken, refreshTokenMaxAge))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "auth.logged_in"), gin.H{"token": tokens.AccessToken}))
+}
+
+// refreshTokenMaxAge matches the lifetime of a session's refresh token.
+const refreshTokenMaxAge = 60 * 60 * 24 * 7
+
+// refreshTokenCookie is only sent to the refresh route; a maxAge of -1 clears it.
+func (ctrl *authController) refreshTokenCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
 		Name:     "refresh_token",
-		Value:    "",
-		Path:     "/auth/refresh",
-		Domain:   "",
-		MaxAge:   -1,
-		Secure:   false,
+		Value:    value,
+		Path:     ctrl.cookies.PathPrefix + "/auth/refresh",
+		MaxAge:   maxAge,
+		Secure:   ctrl.cookies.Secure,
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
 	}
+}
 
-	http.SetCookie(ctx.Writer, cookie)
-	ctx.JSON(http.StatusOK, APIResponse.Success("User logged out successfully", nil))
+func clientInfo(ctx *gin.Context) session.ClientInfo {
+	return session.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
+
+func bearerToken(ctx *gin.Context) string {
+	parts := strings.Split(ctx.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return ""
+	}
+	return parts[1]
 }
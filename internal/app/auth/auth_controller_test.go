@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/mockoidc"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/oidc"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const testCallbackURL = "http://api.example.com/api/auth/oidc/mock/callback"
+
+// newOIDCTestRouter serves the auth routes under /api with a single provider, "mock",
+// backed by an in-process mock OIDC provider.
+func newOIDCTestRouter(t *testing.T) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+
+	provider, err := mockoidc.New(mockoidc.Options{
+		Issuer:       issuer.URL,
+		ClientID:     "event-booking-api",
+		ClientSecret: "secret",
+		Email:        "ada@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux.Handle("/", provider)
+
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	oidcService := oidc.NewOIDCService(client, []oidc.ProviderConfig{{
+		Name:         "mock",
+		Issuer:       issuer.URL,
+		ClientID:     "event-booking-api",
+		ClientSecret: "secret",
+		RedirectURL:  testCallbackURL,
+		Scopes:       []string{"openid", "email"},
+	}})
+	service := NewAuthService(nil, nil, nil, nil, nil, oidcService, Options{}, slog.New(slog.DiscardHandler))
+	controller := NewAuthController(service, nil, CookieOptions{Secure: true, PathPrefix: "/api"})
+
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	RegisterRoutes(router.Group("/api"), controller, func(ctx *gin.Context) {}, func(ctx *gin.Context) {})
+	return router, redisServer
+}
+
+// startOIDCLogin starts a login and has the mock provider approve it, returning the state
+// cookie set by the login route and the callback URL the provider redirected back to.
+func startOIDCLogin(t *testing.T, router *gin.Engine) (*http.Cookie, *url.URL) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/mock/login", nil))
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("login: got status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie {
+		t.Fatalf("login: got cookies %v, want only %s", cookies, oidcStateCookie)
+	}
+
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := noRedirects.Get(recorder.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: got status %d", response.StatusCode)
+	}
+
+	callbackURL, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cookies[0], callbackURL
+}
+
+func callOIDCCallback(router *gin.Engine, callbackURL *url.URL, cookie *http.Cookie) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, callbackURL.RequestURI(), nil)
+	if cookie != nil {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func assertInvalidLoginState(t *testing.T, recorder *httptest.ResponseRecorder) {
+	t.Helper()
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body)
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "invalid_login_state" {
+		t.Fatalf("got code %q, want invalid_login_state", body.Code)
+	}
+}
+
+func TestOIDCLoginSetsScopedSecureStateCookie(t *testing.T) {
+	router, _ := newOIDCTestRouter(t)
+
+	cookie, callbackURL := startOIDCLogin(t, router)
+	if cookie.Path != "/api/auth/oidc/mock/" {
+		t.Fatalf("state cookie path: got %q, want /api/auth/oidc/mock/", cookie.Path)
+	}
+	if !cookie.Secure || !cookie.HttpOnly {
+		t.Fatalf("state cookie: got Secure=%v HttpOnly=%v, want both set", cookie.Secure, cookie.HttpOnly)
+	}
+	if callbackURL.Query().Get("state") != cookie.Value {
+		t.Fatal("the provider did not send back the state held by the cookie")
+	}
+}
+
+func TestOIDCCallbackRejectsMissingStateCookie(t *testing.T) {
+	router, redisServer := newOIDCTestRouter(t)
+	_, callbackURL := startOIDCLogin(t, router)
+
+	assertInvalidLoginState(t, callOIDCCallback(router, callbackURL, nil))
+
+	// The login is rejected before the state is redeemed, so it is still pending.
+	if keys := redisServer.Keys(); len(keys) != 1 {
+		t.Fatalf("got keys %v, want the pending login state", keys)
+	}
+}
+
+func TestOIDCCallbackRejectsMismatchedStateCookie(t *testing.T) {
+	router, _ := newOIDCTestRouter(t)
+
+	// The victim's browser holds the state of its own login, while the callback carries the
+	// code and state of a login the attacker started.
+	victimCookie, _ := startOIDCLogin(t, router)
+	_, attackerCallbackURL := startOIDCLogin(t, router)
+
+	recorder := callOIDCCallback(router, attackerCallbackURL, victimCookie)
+	assertInvalidLoginState(t, recorder)
+
+	cleared := recorder.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].Name != oidcStateCookie || cleared[0].MaxAge >= 0 {
+		t.Fatalf("got cookies %v, want the state cookie cleared", cleared)
+	}
+	if cleared[0].Path != victimCookie.Path {
+		t.Fatalf("cleared cookie path %q does not match the one set, %q", cleared[0].Path, victimCookie.Path)
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"net/http"
+
+	AuthDTO "github.com/edwinedjokpa/event-booking-api/internal/app/auth/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+)
+
+var refreshTokenCookie = openapi.Parameter{In: "cookie", Name: "refresh_token", Description: "Set by the login endpoints", Required: true}
+
+// OpenAPIOperations documents the routes registered by RegisterRoutes.
+func OpenAPIOperations() []openapi.Operation {
+	accessToken := openapi.Data{"token": ""}
+	loginDescription := "Returns an access token and sets the refresh token as an HTTP-only cookie."
+	otpSendDescription := "Limited per client IP with 429. Codes stop being sent to an address after a few per hour, " +
+		"but the response does not say so."
+
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/auth/register", Summary: "Create an account", Body: AuthDTO.RegisterUserRequest{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/auth/login", Summary: "Sign in with email and password", Description: loginDescription, Body: AuthDTO.LoginUserRequest{}, Data: accessToken},
+		{Method: http.MethodPost, Path: "/auth/forgot-password", Summary: "Email a password reset code", Description: otpSendDescription, Body: AuthDTO.ForgotPasswordRequest{}},
+		{Method: http.MethodPost, Path: "/auth/reset-password", Summary: "Reset a password with the emailed code", Body: AuthDTO.ResetPasswordRequest{}},
+		{Method: http.MethodPost, Path: "/auth/logout", Summary: "Sign out of the current session", Parameters: []openapi.Parameter{{In: "cookie", Name: "refresh_token"}}},
+		{Method: http.MethodPost, Path: "/auth/refresh", Summary: "Exchange the refresh token for new tokens", Parameters: []openapi.Parameter{refreshTokenCookie}, Data: accessToken},
+		{Method: http.MethodPost, Path: "/auth/passwordless/start", Summary: "Email a sign-in code", Description: otpSendDescription, Body: AuthDTO.PasswordlessLoginRequest{}},
+		{Method: http.MethodPost, Path: "/auth/passwordless/verify", Summary: "Sign in with an emailed code or link token", Description: loginDescription, Body: AuthDTO.PasswordlessVerifyRequest{}, Data: accessToken},
+		{
+			Method: http.MethodGet, Path: "/auth/oidc/:provider/login", Summary: "Start signing in with an identity provider", Status: http.StatusFound,
+			Description: "Sets the oidc_state cookie, which the callback requires, so sign-in must finish in the same browser.",
+		},
+		{
+			Method: http.MethodGet, Path: "/auth/oidc/:provider/callback", Summary: "Finish signing in with an identity provider",
+			Description: loginDescription,
+			Parameters: []openapi.Parameter{
+				{In: "query", Name: "state"},
+				{In: "query", Name: "code"},
+				{In: "query", Name: "error", Description: "Set by the provider when sign-in was not completed"},
+				{In: "cookie", Name: "oidc_state", Description: "Set by the login route; must match state"},
+			},
+			Data: accessToken,
+		},
+		{Method: http.MethodPost, Path: "/auth/logout-all", Summary: "Sign out of every session", Security: openapi.SecuritySession},
+		{Method: http.MethodGet, Path: "/auth/sessions", Summary: "List active sessions", Security: openapi.SecuritySession, Data: openapi.Data{"sessions": []AuthDTO.SessionResponse{}}},
+		{Method: http.MethodDelete, Path: "/auth/sessions/:id", Summary: "Revoke a session", Security: openapi.SecuritySession},
+	}
+}
+
+// WellKnownOpenAPIOperations documents the routes registered by RegisterWellKnownRoutes.
+func WellKnownOpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/.well-known/jwks.json", Summary: "Public keys for verifying access tokens", Raw: util.JSONWebKeySet{}},
+	}
+}
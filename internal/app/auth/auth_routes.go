@@ -1,12 +1,32 @@
 package auth
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
 
-func RegisterRoutes(router *gin.RouterGroup, controller AuthController) {
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, controller AuthController, authMiddleware, otpRateLimitMiddleware gin.HandlerFunc) {
 	router.POST("/auth/register", controller.Register)
 	router.POST("/auth/login", controller.Login)
-	router.POST("/auth/forgot-password", controller.ForgotPassword)
+	router.POST("/auth/forgot-password", otpRateLimitMiddleware, controller.ForgotPassword)
 	router.POST("/auth/reset-password", controller.ResetPassword)
 	router.POST("/auth/logout", controller.Logout)
 	router.POST("/auth/refresh", controller.RefreshToken)
+	router.POST("/auth/passwordless/start", otpRateLimitMiddleware, controller.StartPasswordlessLogin)
+	router.POST("/auth/passwordless/verify", controller.PasswordlessLogin)
+	router.GET("/auth/oidc/:provider/login", controller.OIDCLogin)
+	router.GET("/auth/oidc/:provider/callback", controller.OIDCCallback)
+
+	authRouter := router.Group("/auth")
+	authRouter.Use(authMiddleware, middleware.RequireSession())
+	{
+		authRouter.POST("/logout-all", controller.LogoutAll)
+		authRouter.GET("/sessions", controller.GetSessions)
+		authRouter.DELETE("/sessions/:id", controller.RevokeSession)
+	}
+}
+
+func RegisterWellKnownRoutes(router *gin.RouterGroup, controller AuthController) {
+	router.GET("/.well-known/jwks.json", controller.JWKS)
 }
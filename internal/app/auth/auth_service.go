@@ -4,55 +4,106 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
 	AuthDTO "github.com/edwinedjokpa/event-booking-api/internal/app/auth/dto"
 	"github.com/edwinedjokpa/event-booking-api/internal/app/user"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/metrics"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/denylist"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/oidc"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/otp"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/session"
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
 
 	"github.com/golang-jwt/jwt/v4"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidCredentials      = apperror.Unauthorized("invalid_credentials", "Invalid credentials")
+	ErrInvalidRefreshToken     = apperror.Unauthorized("invalid_refresh_token", "Invalid refresh token")
+	ErrSessionExpired          = apperror.Unauthorized("session_expired", "Session expired or revoked")
+	ErrSessionNotFound         = apperror.NotFound("session_not_found", "Session not found")
+	ErrProviderLoginFailed     = apperror.Unauthorized("identity_provider_login_failed", "Login with identity provider failed")
+	ErrProviderEmailUnverified = apperror.Validation("identity_provider_email_unverified", "Identity provider did not return a verified email address")
+	ErrProviderLoginIncomplete = apperror.Validation("identity_provider_login_incomplete", "Login with identity provider was not completed")
+	ErrProviderCallbackInvalid = apperror.Validation("identity_provider_callback_invalid", "State and code are required")
+	ErrRefreshTokenMissing     = apperror.Unauthorized("refresh_token_missing", "Refresh token is missing")
+	// ErrProviderAccountUnverified is returned instead of linking a provider account to a local
+	// account whose email was never verified, which may have been registered by someone else.
+	// Resetting the password verifies the email and signs out anyone who knew the old one.
+	ErrProviderAccountUnverified = apperror.Conflict("identity_provider_account_unverified", "An account with this email already exists. Verify the email by resetting the account's password, then sign in with the identity provider again")
+	// ErrUnavailable is returned when the session or code store cannot be reached.
+	ErrUnavailable = apperror.Unavailable("auth_unavailable", "Authentication is temporarily unavailable, please try again")
+)
+
 type AuthService interface {
-	Register(request AuthDTO.RegisterUserRequest)
-	Login(ctx context.Context, request AuthDTO.LoginUserRequest) AuthDTO.LoginResponse
-	ForgotPassword(request AuthDTO.ForgotPasswordRequest)
-	ResetPassword(request AuthDTO.ResetPasswordRequest)
-	Logout(ctx context.Context, token string)
-	RefreshToken(ctx context.Context, token string) AuthDTO.LoginResponse
+	Register(ctx context.Context, request AuthDTO.RegisterUserRequest) error
+	Login(ctx context.Context, request AuthDTO.LoginUserRequest, client session.ClientInfo) (*AuthDTO.LoginResponse, error)
+	ForgotPassword(ctx context.Context, request AuthDTO.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request AuthDTO.ResetPasswordRequest) error
+	Logout(ctx context.Context, refreshToken, accessToken string) error
+	LogoutAll(ctx context.Context, userID string) error
+	RefreshToken(ctx context.Context, token string, client session.ClientInfo) (*AuthDTO.LoginResponse, error)
+	GetSessions(ctx context.Context, userID, currentSessionID string) ([]AuthDTO.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	GetJWKS() util.JSONWebKeySet
+	StartPasswordlessLogin(ctx context.Context, request AuthDTO.PasswordlessLoginRequest) error
+	PasswordlessLogin(ctx context.Context, request AuthDTO.PasswordlessVerifyRequest, client session.ClientInfo) (*AuthDTO.LoginResponse, error)
+	OIDCAuthURL(ctx context.Context, provider string) (authURL, state string, err error)
+	OIDCLogin(ctx context.Context, provider, state, code string, client session.ClientInfo) (*AuthDTO.LoginResponse, error)
 }
 
+const (
+	accessTokenExpiresAt  = 15 * time.Minute
+	refreshTokenExpiresAt = 7 * 24 * time.Hour
+)
+
 type authService struct {
-	repository     user.UserRepository
-	jwtSecret      string
-	sessionService *session.SessionService
-	otpService     otp.OTPService
+	repository      user.UserRepository
+	keySet          *util.KeySet
+	sessionService  *session.SessionService
+	otpService      otp.OTPService
+	denylistService *denylist.DenylistService
+	oidcService     *oidc.OIDCService
+	options         Options
+	logger          *slog.Logger
+}
+
+// Options holds the configurable parts of the authentication flows.
+type Options struct {
+	// PasswordlessSignup creates an account on the first passwordless login for an unknown email.
+	PasswordlessSignup bool
+	// PasswordlessLinkURL is the page sign-in links point at. Links are not sent when it is empty.
+	PasswordlessLinkURL string
 }
 
-func NewAuthService(repository user.UserRepository, jwtSecret string, sessionService *session.SessionService, otpService otp.OTPService) AuthService {
-	return &authService{repository, jwtSecret, sessionService, otpService}
+func NewAuthService(repository user.UserRepository, keySet *util.KeySet, sessionService *session.SessionService, otpService otp.OTPService, denylistService *denylist.DenylistService, oidcService *oidc.OIDCService, options Options, logger *slog.Logger) AuthService {
+	return &authService{repository, keySet, sessionService, otpService, denylistService, oidcService, options, logger}
 }
 
-func (svc *authService) Register(request AuthDTO.RegisterUserRequest) {
+func (svc *authService) Register(ctx context.Context, request AuthDTO.RegisterUserRequest) error {
 	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
 
-	existingUser, dbErr := svc.repository.FindOneByEmail(normalizedEmail)
+	existingUser, dbErr := svc.repository.FindOneByEmail(ctx, normalizedEmail)
 	if dbErr != nil && !errors.Is(dbErr, gorm.ErrRecordNotFound) {
-		panic(dbErr)
+		return dbErr
 	}
 
 	if existingUser != nil {
-		panic(HTTPException.NewConflictException("User with email already exists", nil))
+		return user.ErrEmailTaken
 	}
 
 	hashedPassword, err := util.HashPassword(request.Password)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to hash user password", err.Error()))
+		return fmt.Errorf("hashing password: %w", err)
 	}
 
 	newUser := user.User{
@@ -61,17 +112,21 @@ func (svc *authService) Register(request AuthDTO.RegisterUserRequest) {
 		LastName:  request.LastName,
 		Email:     normalizedEmail,
 		Password:  string(hashedPassword),
+		Role:      rbac.RoleAttendee,
 	}
 
-	if err := svc.repository.Create(newUser); err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to create user account", err.Error()))
+	if err := svc.repository.Create(ctx, newUser); err != nil {
+		return fmt.Errorf("creating user: %w", err)
 	}
+
+	metrics.UserRegistrationsTotal.WithLabelValues("password").Inc()
+	return nil
 }
 
-func (svc *authService) Login(ctx context.Context, request AuthDTO.LoginUserRequest) AuthDTO.LoginResponse {
+func (svc *authService) Login(ctx context.Context, request AuthDTO.LoginUserRequest, client session.ClientInfo) (*AuthDTO.LoginResponse, error) {
 	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
 
-	user, dbErr := svc.repository.FindOneByEmail(normalizedEmail)
+	user, dbErr := svc.repository.FindOneByEmail(ctx, normalizedEmail)
 
 	var storedPassword string
 	if user != nil {
@@ -81,149 +136,582 @@ func (svc *authService) Login(ctx context.Context, request AuthDTO.LoginUserRequ
 	}
 
 	if dbErr != nil && !errors.Is(dbErr, gorm.ErrRecordNotFound) {
-		panic(dbErr)
+		return nil, dbErr
 	}
 
 	isValid := util.CheckPasswordHash(storedPassword, request.Password)
 	if user == nil || !isValid {
-		panic(HTTPException.NewBadRequestException("Invalid credentials", nil))
+		metrics.FailedLoginsTotal.WithLabelValues("password").Inc()
+		return nil, ErrInvalidCredentials
+	}
+
+	metrics.LoginsTotal.WithLabelValues("password").Inc()
+	return svc.startSession(ctx, user, client)
+}
+
+// startSession opens a new session for a user who has just authenticated.
+func (svc *authService) startSession(ctx context.Context, user *user.User, client session.ClientInfo) (*AuthDTO.LoginResponse, error) {
+	now := time.Now().UTC()
+	sessionData := session.SessionData{
+		ID:         util.GenerateUUID(),
+		UserID:     user.ID,
+		Email:      user.Email,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
 	}
 
-	accessExpiresAt := 15 * time.Minute
-	accessClaims := jwt.MapClaims{"userID": user.ID, "email": user.Email}
-	accessToken, err := util.GenerateToken(accessClaims, accessExpiresAt, svc.jwtSecret)
+	return svc.issueTokens(ctx, user, sessionData)
+}
+
+// issueTokens persists the session and returns a fresh access/refresh token pair bound to it.
+// The new refresh token becomes the only one in the session's family that may be exchanged.
+func (svc *authService) issueTokens(ctx context.Context, user *user.User, sessionData session.SessionData) (*AuthDTO.LoginResponse, error) {
+	tokens, _, err := svc.rotateTokens(ctx, user, sessionData, "")
+	return tokens, err
+}
+
+// rotateTokens issues a new token pair for the session. With a previousRefreshTokenID the
+// session is only replaced while it still holds that refresh token, and rotated reports
+// whether it did; otherwise the session is stored unconditionally.
+func (svc *authService) rotateTokens(ctx context.Context, user *user.User, sessionData session.SessionData, previousRefreshTokenID string) (_ *AuthDTO.LoginResponse, rotated bool, err error) {
+	accessClaims := jwt.MapClaims{
+		"typ":           "access",
+		"userID":        user.ID,
+		"email":         user.Email,
+		"emailVerified": user.EmailVerifiedAt != nil,
+		"sessionID":     sessionData.ID,
+		"role":          user.Role,
+		"permissions":   user.Role.Permissions(),
+		"locale":        user.Locale,
+	}
+	accessToken, err := util.GenerateToken(accessClaims, accessTokenExpiresAt, svc.keySet)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to generate access token", err.Error()))
+		return nil, false, fmt.Errorf("generating access token: %w", err)
 	}
 
-	refreshSessionID := util.GenerateUUID()
-	refreshExpiresAt := 7 * 24 * time.Hour
-	refreshClaims := jwt.MapClaims{"sessionID": refreshSessionID}
-	refreshToken, err := util.GenerateToken(refreshClaims, refreshExpiresAt, svc.jwtSecret)
+	sessionData.RefreshTokenID = util.GenerateUUID()
+	refreshClaims := jwt.MapClaims{"typ": "refresh", "sessionID": sessionData.ID, "jti": sessionData.RefreshTokenID}
+	refreshToken, err := util.GenerateToken(refreshClaims, refreshTokenExpiresAt, svc.keySet)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to generate refresh token", err.Error()))
+		return nil, false, fmt.Errorf("generating refresh token: %w", err)
 	}
 
-	err = svc.sessionService.SetSession(ctx, refreshSessionID, user.ID, user.Email, refreshExpiresAt)
+	if previousRefreshTokenID == "" {
+		err = svc.sessionService.SetSession(ctx, sessionData, refreshTokenExpiresAt)
+		rotated = true
+	} else {
+		rotated, err = svc.sessionService.RotateSession(ctx, sessionData, previousRefreshTokenID, refreshTokenExpiresAt)
+	}
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to create session", err.Error()))
+		return nil, false, ErrUnavailable.WithCause(err)
+	}
+	if !rotated {
+		return nil, false, nil
 	}
 
-	return AuthDTO.LoginResponse{
+	return &AuthDTO.LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-	}
+	}, true, nil
 }
 
-func (svc *authService) ForgotPassword(request AuthDTO.ForgotPasswordRequest) {
+func (svc *authService) ForgotPassword(ctx context.Context, request AuthDTO.ForgotPasswordRequest) error {
 	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
 
-	user, err := svc.repository.FindOneByEmail(normalizedEmail)
+	user, err := svc.repository.FindOneByEmail(ctx, normalizedEmail)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			panic(err)
+			return err
 		}
-		return
+		return nil
 	}
 
-	otp, err := svc.otpService.GenerateAndStoreOTP(user.Email)
+	code, err := svc.otpService.GenerateAndStoreOTP(ctx, otp.PurposePasswordReset, user.Email)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to generate OTP", err.Error()))
+		return svc.otpNotSent(ctx, user.Email, err)
 	}
 
-	fmt.Printf("OTP for %s is: %s (expires in 15 minutes)\n", user.Email, otp)
+	locale := i18n.Match(user.Locale)
+	logging.FromContext(ctx, svc.logger).Info("Password reset OTP issued",
+		slog.String("email", user.Email),
+		slog.String("locale", locale),
+		slog.String("subject", i18n.Message(locale, "email.password_reset.subject")),
+		slog.String("otp", code),
+	)
+	return nil
 }
 
-func (svc *authService) ResetPassword(request AuthDTO.ResetPasswordRequest) {
+func (svc *authService) ResetPassword(ctx context.Context, request AuthDTO.ResetPasswordRequest) error {
 	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
 
-	user, dbErr := svc.repository.FindOneByEmail(normalizedEmail)
-	otpErr := svc.otpService.ValidateOTP(normalizedEmail, request.OTP)
+	user, dbErr := svc.repository.FindOneByEmail(ctx, normalizedEmail)
+	otpErr := svc.otpService.ValidateOTP(ctx, otp.PurposePasswordReset, normalizedEmail, request.OTP)
+
+	// Misses are counted for unknown addresses too, so this does not reveal whether one exists.
+	if errors.Is(otpErr, otp.ErrTooManyOTPAttempts) {
+		return otp.ErrTooManyOTPAttempts
+	}
 
 	isUserNotFound := errors.Is(dbErr, gorm.ErrRecordNotFound)
-	if isUserNotFound || otpErr != nil {
-		panic(HTTPException.NewBadRequestException("Invalid or expired OTP", nil))
+	if isUserNotFound || errors.Is(otpErr, otp.ErrInvalidOTP) {
+		return otp.ErrInvalidOTP
 	}
 
 	if dbErr != nil {
-		panic(dbErr)
+		return dbErr
+	}
+
+	if otpErr != nil {
+		return ErrUnavailable.WithCause(otpErr)
 	}
 
 	newHashedPassword, err := util.HashPassword(request.NewPassword)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to hash new password", err))
+		return fmt.Errorf("hashing password: %w", err)
 	}
 
-	err = svc.repository.UpdatePassword(user.ID, newHashedPassword)
-	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to update password", err))
+	if err := svc.repository.UpdatePassword(ctx, user.ID, newHashedPassword); err != nil {
+		return fmt.Errorf("updating password: %w", err)
 	}
+
+	// The code reached the user's inbox, which proves they receive mail there.
+	if err := svc.markEmailVerified(ctx, user); err != nil {
+		return err
+	}
+
+	return svc.revokeAllSessions(ctx, user.ID)
 }
 
-func (svc *authService) RefreshToken(ctx context.Context, tokenString string) AuthDTO.LoginResponse {
-	_, claims, err := util.ValidateToken(tokenString, []byte(svc.jwtSecret))
-	if err != nil {
-		panic(HTTPException.NewUnauthorizedException("Invalid refresh token", nil))
+func (svc *authService) RefreshToken(ctx context.Context, tokenString string, client session.ClientInfo) (*AuthDTO.LoginResponse, error) {
+	_, claims, err := util.ValidateToken(tokenString, svc.keySet)
+	if err != nil || claims["typ"] != "refresh" {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	sessionIDRaw, exists := claims["sessionID"]
-	if !exists {
-		panic(HTTPException.NewBadRequestException("Invalid session", nil))
+	sessionID, ok := claims["sessionID"].(string)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	sessionID, ok := sessionIDRaw.(string)
+	tokenID, ok := claims["jti"].(string)
 	if !ok {
-		panic(HTTPException.NewUnauthorizedException("Invalid session", nil))
+		return nil, ErrInvalidRefreshToken
 	}
 
 	sessionData, err := svc.sessionService.GetSession(ctx, sessionID)
 	if err != nil {
-		panic(HTTPException.NewUnauthorizedException("Session expired or revoked", nil))
+		return nil, ErrUnavailable.WithCause(err)
 	}
 
-	if err := svc.sessionService.DeleteSession(ctx, sessionID); err != nil {
-		panic(HTTPException.NewBadRequestException("failed to delete sessionI D", nil))
+	if sessionData == nil {
+		return nil, ErrSessionExpired
 	}
 
-	newSessionID := util.GenerateUUID()
-	refreshExpiresAt := 7 * 24 * time.Hour
-
-	if err := svc.sessionService.SetSession(ctx, newSessionID, sessionData.UserID, sessionData.Email, refreshExpiresAt); err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to save new refresh session", nil))
+	if tokenID != sessionData.RefreshTokenID {
+		return nil, svc.refreshTokenReused(ctx, *sessionData, client)
 	}
 
-	accessExpiresAt := 1 * time.Hour
-	accessClaims := jwt.MapClaims{"userID": sessionData.UserID, "email": sessionData.Email}
-	newAccessToken, err := util.GenerateToken(accessClaims, accessExpiresAt, svc.jwtSecret)
+	// Reload the user so that role changes are reflected in the new access token.
+	user, err := svc.repository.FindOneByID(ctx, sessionData.UserID)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to generate new access token", nil))
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err := svc.revokeSession(ctx, sessionData.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrSessionExpired
 	}
 
-	refreshClaims := jwt.MapClaims{"sessionID": newSessionID}
-	newRefreshToken, err := util.GenerateToken(refreshClaims, refreshExpiresAt, svc.jwtSecret)
+	sessionData.Email = user.Email
+	sessionData.UserAgent = client.UserAgent
+	sessionData.IPAddress = client.IPAddress
+	sessionData.LastUsedAt = time.Now().UTC()
+
+	// The check above and the rotation are not atomic, so the rotation itself only succeeds
+	// while the session still holds this token. Losing it means a concurrent replay.
+	tokens, rotated, err := svc.rotateTokens(ctx, user, *sessionData, tokenID)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to generate new refresh token", nil))
+		return nil, err
+	}
+	if !rotated {
+		return nil, svc.refreshTokenReused(ctx, *sessionData, client)
 	}
+	return tokens, nil
+}
 
-	return AuthDTO.LoginResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
+// refreshTokenReused handles a refresh token that has already been rotated out. Either the
+// client or an attacker holds a stolen copy, so the whole family is revoked to be safe.
+func (svc *authService) refreshTokenReused(ctx context.Context, sessionData session.SessionData, client session.ClientInfo) error {
+	logging.FromContext(ctx, svc.logger).Warn("Refresh token reuse detected, revoking session",
+		slog.String("user_id", sessionData.UserID),
+		slog.String("session_id", sessionData.ID),
+		slog.String("client_ip", client.IPAddress),
+		slog.String("user_agent", client.UserAgent),
+	)
+	if err := svc.revokeSession(ctx, sessionData.ID); err != nil {
+		return err
 	}
+	return ErrSessionExpired
 }
 
-func (svc *authService) Logout(ctx context.Context, refreshToken string) {
-	_, claims, err := util.ValidateToken(refreshToken, []byte(svc.jwtSecret))
+func (svc *authService) Logout(ctx context.Context, refreshToken, accessToken string) error {
+	if accessToken != "" {
+		if _, claims, err := util.ValidateToken(accessToken, svc.keySet); err == nil {
+			tokenID, _ := claims["jti"].(string)
+			if err := svc.denylistService.RevokeToken(ctx, tokenID, util.TokenTTL(claims)); err != nil {
+				return ErrUnavailable.WithCause(err)
+			}
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	_, claims, err := util.ValidateToken(refreshToken, svc.keySet)
 	if err != nil {
-		panic(HTTPException.NewUnauthorizedException("Invalid refresh token", nil))
+		return ErrInvalidRefreshToken
 	}
 
 	sessionID, ok := claims["sessionID"].(string)
 	if !ok {
-		panic(HTTPException.NewUnauthorizedException("invalid session ID in refresh token claims", nil))
+		return ErrInvalidRefreshToken
+	}
+
+	return svc.revokeSession(ctx, sessionID)
+}
+
+func (svc *authService) LogoutAll(ctx context.Context, userID string) error {
+	return svc.revokeAllSessions(ctx, userID)
+}
+
+func (svc *authService) GetSessions(ctx context.Context, userID, currentSessionID string) ([]AuthDTO.SessionResponse, error) {
+	sessions, err := svc.sessionService.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, ErrUnavailable.WithCause(err)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	response := make([]AuthDTO.SessionResponse, 0, len(sessions))
+	for _, sessionData := range sessions {
+		response = append(response, AuthDTO.SessionResponse{
+			ID:         sessionData.ID,
+			UserAgent:  sessionData.UserAgent,
+			IPAddress:  sessionData.IPAddress,
+			CreatedAt:  sessionData.CreatedAt,
+			LastUsedAt: sessionData.LastUsedAt,
+			Current:    sessionData.ID == currentSessionID,
+		})
+	}
+
+	return response, nil
+}
+
+func (svc *authService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	found, err := svc.sessionService.DeleteUserSession(ctx, userID, sessionID)
+	if err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	if !found {
+		return ErrSessionNotFound
+	}
+
+	if err := svc.denylistService.RevokeSession(ctx, sessionID); err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	return nil
+}
+
+// revokeSession deletes the session and denylists any access tokens still outstanding for it.
+func (svc *authService) revokeSession(ctx context.Context, sessionID string) error {
+	if err := svc.sessionService.DeleteSession(ctx, sessionID); err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	if err := svc.denylistService.RevokeSession(ctx, sessionID); err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	return nil
+}
+
+func (svc *authService) revokeAllSessions(ctx context.Context, userID string) error {
+	sessionIDs, err := svc.sessionService.DeleteAllUserSessions(ctx, userID)
+	if err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := svc.denylistService.RevokeSession(ctx, sessionID); err != nil {
+			return ErrUnavailable.WithCause(err)
+		}
+	}
+
+	return nil
+}
+
+func (svc *authService) GetJWKS() util.JSONWebKeySet {
+	return svc.keySet.JWKS()
+}
+
+// StartPasswordlessLogin emails a sign-in code, and a sign-in link when configured. Nothing
+// is sent for unknown addresses unless signup is enabled, and the caller is not told which.
+func (svc *authService) StartPasswordlessLogin(ctx context.Context, request AuthDTO.PasswordlessLoginRequest) error {
+	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
+
+	// New users get the email in the request's locale.
+	locale := i18n.FromContext(ctx)
+
+	existingUser, err := svc.repository.FindOneByEmail(ctx, normalizedEmail)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if !svc.options.PasswordlessSignup {
+			return nil
+		}
+	} else {
+		locale = i18n.Match(existingUser.Locale)
+	}
+
+	code, err := svc.otpService.GenerateAndStoreOTP(ctx, otp.PurposeLogin, normalizedEmail)
+	if err != nil {
+		return svc.otpNotSent(ctx, normalizedEmail, err)
+	}
+
+	logging.FromContext(ctx, svc.logger).Info("Sign-in OTP issued",
+		slog.String("email", normalizedEmail),
+		slog.String("locale", locale),
+		slog.String("subject", i18n.Message(locale, "email.sign_in.subject")),
+		slog.String("otp", code),
+	)
+
+	if svc.options.PasswordlessLinkURL == "" {
+		return nil
+	}
+
+	token, err := svc.otpService.GenerateAndStoreLinkToken(ctx, otp.PurposeLogin, normalizedEmail)
+	if err != nil {
+		return ErrUnavailable.WithCause(err)
+	}
+
+	logging.FromContext(ctx, svc.logger).Info("Sign-in link issued",
+		slog.String("email", normalizedEmail),
+		slog.Any("link", logging.Secret(svc.options.PasswordlessLinkURL+"?token="+token)),
+	)
+	return nil
+}
+
+func (svc *authService) PasswordlessLogin(ctx context.Context, request AuthDTO.PasswordlessVerifyRequest, client session.ClientInfo) (*AuthDTO.LoginResponse, error) {
+	var normalizedEmail string
+	var err error
+
+	if request.Token != "" {
+		normalizedEmail, err = svc.otpService.ConsumeLinkToken(ctx, otp.PurposeLogin, request.Token)
+	} else {
+		normalizedEmail = strings.ToLower(strings.TrimSpace(request.Email))
+		err = svc.otpService.ValidateOTP(ctx, otp.PurposeLogin, normalizedEmail, request.OTP)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, otp.ErrInvalidOTP):
+			metrics.FailedLoginsTotal.WithLabelValues("passwordless").Inc()
+			return nil, otp.ErrInvalidOTP
+		case errors.Is(err, otp.ErrTooManyOTPAttempts):
+			metrics.FailedLoginsTotal.WithLabelValues("passwordless").Inc()
+			return nil, otp.ErrTooManyOTPAttempts
+		}
+		return nil, ErrUnavailable.WithCause(err)
+	}
+
+	existingUser, err := svc.repository.FindOneByEmail(ctx, normalizedEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		if err := svc.markEmailVerified(ctx, existingUser); err != nil {
+			return nil, err
+		}
+		metrics.LoginsTotal.WithLabelValues("passwordless").Inc()
+		return svc.startSession(ctx, existingUser, client)
+	}
+
+	if !svc.options.PasswordlessSignup {
+		metrics.FailedLoginsTotal.WithLabelValues("passwordless").Inc()
+		return nil, otp.ErrInvalidOTP
+	}
+
+	// Lightweight accounts start without a usable password and with the email's local part
+	// as their name; both can be set later from the profile.
+	unusablePassword, err := util.HashPassword(util.GenerateUUID())
+	if err != nil {
+		return nil, fmt.Errorf("hashing password: %w", err)
+	}
+
+	firstName, _, _ := strings.Cut(normalizedEmail, "@")
+	verifiedAt := time.Now()
+	newUser := user.User{
+		ID:              util.GenerateUUID(),
+		FirstName:       firstName,
+		Email:           normalizedEmail,
+		EmailVerifiedAt: &verifiedAt,
+		Password:        unusablePassword,
+		Role:            rbac.RoleAttendee,
+	}
+
+	if err := svc.repository.Create(ctx, newUser); err != nil {
+		return nil, fmt.Errorf("creating user: %w", err)
 	}
 
-	err = svc.sessionService.DeleteSession(ctx, sessionID)
+	metrics.UserRegistrationsTotal.WithLabelValues("passwordless").Inc()
+	metrics.LoginsTotal.WithLabelValues("passwordless").Inc()
+	return svc.startSession(ctx, &newUser, client)
+}
+
+func (svc *authService) OIDCAuthURL(ctx context.Context, provider string) (string, string, error) {
+	authURL, state, err := svc.oidcService.AuthURL(ctx, provider)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to delete session", err.Error()))
+		if errors.Is(err, oidc.ErrUnknownProvider) {
+			return "", "", oidc.ErrUnknownProvider
+		}
+		return "", "", ErrUnavailable.WithCause(err)
+	}
+
+	return authURL, state, nil
+}
+
+// OIDCLogin completes a login at an external identity provider. The provider account is
+// resolved to a user through a previously linked identity, then by verified email on both
+// sides, and otherwise a new user is created; the session is then started exactly as for Login.
+func (svc *authService) OIDCLogin(ctx context.Context, provider, state, code string, client session.ClientInfo) (*AuthDTO.LoginResponse, error) {
+	claims, err := svc.oidcService.Exchange(ctx, provider, state, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrUnknownProvider):
+			return nil, oidc.ErrUnknownProvider
+		case errors.Is(err, oidc.ErrInvalidState):
+			metrics.FailedLoginsTotal.WithLabelValues("oidc").Inc()
+			return nil, oidc.ErrInvalidState
+		}
+		metrics.FailedLoginsTotal.WithLabelValues("oidc").Inc()
+		logging.FromContext(ctx, svc.logger).Warn("OIDC login failed", slog.String("provider", provider), slog.Any("error", err))
+		return nil, ErrProviderLoginFailed
+	}
+
+	linkedUser, err := svc.repository.FindOneByIdentity(ctx, provider, claims.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if linkedUser != nil {
+		metrics.LoginsTotal.WithLabelValues("oidc").Inc()
+		return svc.startSession(ctx, linkedUser, client)
+	}
+
+	// Without a verified email we cannot tell whether the provider account belongs to an
+	// existing user, and linking on an unverified address would allow account takeover.
+	normalizedEmail := strings.ToLower(strings.TrimSpace(claims.Email))
+	if normalizedEmail == "" || !claims.EmailVerified {
+		metrics.FailedLoginsTotal.WithLabelValues("oidc").Inc()
+		return nil, ErrProviderEmailUnverified
+	}
+
+	identity := user.UserIdentity{
+		ID:        util.GenerateUUID(),
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     normalizedEmail,
+		CreatedAt: time.Now(),
+	}
+
+	existingUser, err := svc.repository.FindOneByEmail(ctx, normalizedEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if existingUser != nil {
+		// Anyone can register with an address they do not own, so linking to an unverified
+		// account would hand it to whoever registered it along with the provider account.
+		if existingUser.EmailVerifiedAt == nil {
+			metrics.FailedLoginsTotal.WithLabelValues("oidc").Inc()
+			return nil, ErrProviderAccountUnverified
+		}
+
+		identity.UserID = existingUser.ID
+		if err := svc.repository.CreateIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("linking identity: %w", err)
+		}
+		metrics.LoginsTotal.WithLabelValues("oidc").Inc()
+		return svc.startSession(ctx, existingUser, client)
+	}
+
+	// Accounts created this way have no usable password until the user resets it.
+	unusablePassword, err := util.HashPassword(util.GenerateUUID())
+	if err != nil {
+		return nil, fmt.Errorf("hashing password: %w", err)
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" {
+		firstName, lastName, _ = strings.Cut(claims.Name, " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(normalizedEmail, "@")
+	}
+
+	verifiedAt := time.Now()
+	newUser := user.User{
+		ID:              util.GenerateUUID(),
+		FirstName:       firstName,
+		LastName:        lastName,
+		Email:           normalizedEmail,
+		EmailVerifiedAt: &verifiedAt,
+		Password:        unusablePassword,
+		Role:            rbac.RoleAttendee,
+	}
+	identity.UserID = newUser.ID
+
+	if err := svc.repository.CreateWithIdentity(ctx, newUser, identity); err != nil {
+		return nil, fmt.Errorf("creating user: %w", err)
+	}
+
+	metrics.UserRegistrationsTotal.WithLabelValues("oidc").Inc()
+	metrics.LoginsTotal.WithLabelValues("oidc").Inc()
+
+	return svc.startSession(ctx, &newUser, client)
+}
+
+// otpNotSent handles a failure to issue a code for an unauthenticated request. Reporting that
+// too many codes were sent would reveal that the address has an account, so the request
+// succeeds as if the code had been sent.
+func (svc *authService) otpNotSent(ctx context.Context, email string, err error) error {
+	if errors.Is(err, otp.ErrTooManyOTPRequests) {
+		logging.FromContext(ctx, svc.logger).Warn("Too many OTPs requested, not sending another", slog.String("email", email))
+		return nil
+	}
+	return ErrUnavailable.WithCause(err)
+}
+
+// markEmailVerified records that the user has just used a code or link sent to their email.
+func (svc *authService) markEmailVerified(ctx context.Context, user *user.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := svc.repository.MarkEmailVerified(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("marking email verified: %w", err)
 	}
+	user.EmailVerifiedAt = &now
+	return nil
 }
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RegisterUserRequest struct {
 	FirstName string `json:"first_name" validate:"required,min=3"`
 	LastName  string `json:"last_name" validate:"required,min=3"`
@@ -26,3 +28,24 @@ type ResetPasswordRequest struct {
 	OTP         string `json:"otp" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
+
+type PasswordlessLoginRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// PasswordlessVerifyRequest carries either the emailed code with its address, or the
+// token from the emailed sign-in link.
+type PasswordlessVerifyRequest struct {
+	Email string `json:"email" validate:"required_without=Token,omitempty,email"`
+	OTP   string `json:"otp" validate:"required_with=Email"`
+	Token string `json:"token" validate:"required_without=Email"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
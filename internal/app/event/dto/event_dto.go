@@ -17,3 +17,16 @@ type UpdateEventRequest struct {
 	Location    *string    `json:"location"`
 	Date        *time.Time `json:"date"`
 }
+
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=co_owner editor checkin_staff viewer"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=co_owner editor checkin_staff viewer"`
+}
+
+type TransferOwnershipRequest struct {
+	MemberID string `json:"member_id" validate:"required"`
+}
@@ -1,6 +1,9 @@
 package event
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
 	"time"
 )
 
@@ -14,4 +17,27 @@ type Event struct {
 	CreatedAt   time.Time  `gorm:"not null" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"not null" json:"updated_at"`
 	DeletedAt   *time.Time `gorm:"default:NULL" json:"deleted_at,omitempty"`
+	// Version is incremented by every update, so that an update based on an outdated copy of
+	// the event can be rejected instead of overwriting the changes made since.
+	Version int64 `gorm:"not null;default:1" json:"version"`
+}
+
+// ETag returns the entity tag of the event's current version.
+func (e *Event) ETag() string {
+	return ETag(e.Version)
+}
+
+// ETag returns the entity tag of an event version, as sent in ETag and If-Match headers.
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ListETag returns an entity tag for a list of events, which changes whenever an event in
+// it is added, removed or updated.
+func ListETag(events []Event) string {
+	hash := sha256.New()
+	for _, event := range events {
+		hash.Write([]byte(event.ID + ":" + strconv.FormatInt(event.Version, 10) + "\n"))
+	}
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil))[:32])
 }
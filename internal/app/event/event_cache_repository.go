@@ -0,0 +1,191 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	eventCacheKeyPrefix = "event_cache:event:"
+	allEventsCacheKey   = "event_cache:all"
+
+	// eventCacheTTL bounds how long an entry can outlive a write that failed to invalidate
+	// it, or a write made without going through the cached repositories. Generations are
+	// kept as long, so that a fill started before an invalidation cannot outlive it.
+	eventCacheTTL = 5 * time.Minute
+
+	generationKeySuffix = ":generation"
+)
+
+// EventCache holds the events read through the cached repositories. Every write through
+// them invalidates the entries it changes. Each entry has a generation that invalidation
+// increments, and a read only fills the entry if its generation is unchanged since the
+// miss, so that a read racing a write cannot cache what the write replaced.
+type EventCache struct {
+	client *redis.Client
+	logger *slog.Logger
+}
+
+func NewEventCache(client *redis.Client, logger *slog.Logger) *EventCache {
+	return &EventCache{client: client, logger: logger}
+}
+
+// get decodes the entry for key into value and reports whether it was found. On a miss it
+// also returns the generation to pass to set. Redis failures are logged and treated as
+// misses, so reads fall back to the database.
+func (c *EventCache) get(ctx context.Context, key string, value interface{}) (bool, string) {
+	values, err := c.client.MGet(ctx, key, key+generationKeySuffix).Result()
+	if err != nil {
+		metrics.EventCacheLookupsTotal.WithLabelValues("error").Inc()
+		logging.FromContext(ctx, c.logger).Warn("Failed to read event cache", slog.String("key", key), slog.Any("error", err))
+		// An empty generation only matches while the entry has never been invalidated.
+		return false, ""
+	}
+
+	generation, _ := values[1].(string)
+	data, ok := values[0].(string)
+	if !ok {
+		metrics.EventCacheLookupsTotal.WithLabelValues("miss").Inc()
+		return false, generation
+	}
+
+	if err := json.Unmarshal([]byte(data), value); err != nil {
+		metrics.EventCacheLookupsTotal.WithLabelValues("error").Inc()
+		logging.FromContext(ctx, c.logger).Warn("Failed to read event cache", slog.String("key", key), slog.Any("error", err))
+		return false, generation
+	}
+
+	metrics.EventCacheLookupsTotal.WithLabelValues("hit").Inc()
+	return true, ""
+}
+
+// fillScript sets an entry only while its generation is still the one seen on the miss.
+var fillScript = redis.NewScript(`
+if (redis.call("GET", KEYS[2]) or "") ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// set fills the entry for key, unless it was invalidated since get returned generation.
+func (c *EventCache) set(ctx context.Context, key, generation string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = fillScript.Run(ctx, c.client, []string{key, key + generationKeySuffix},
+			generation, data, eventCacheTTL.Milliseconds()).Err()
+	}
+	if err != nil {
+		logging.FromContext(ctx, c.logger).Warn("Failed to fill event cache", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// invalidate removes the event and the list of all events, and moves both to a new
+// generation so that fills already in flight are dropped. The write it follows has already
+// happened, so it runs even if the request was cancelled, and a failure is only logged.
+func (c *EventCache) invalidate(ctx context.Context, eventID string) {
+	keys := []string{allEventsCacheKey}
+	if eventID != "" {
+		keys = append(keys, eventCacheKeyPrefix+eventID)
+	}
+
+	invalidateCtx := context.WithoutCancel(ctx)
+	_, err := c.client.TxPipelined(invalidateCtx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Incr(invalidateCtx, key+generationKeySuffix)
+			pipe.PExpire(invalidateCtx, key+generationKeySuffix, eventCacheTTL)
+			pipe.Del(invalidateCtx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx, c.logger).Error("Failed to invalidate event cache", slog.String("event_id", eventID), slog.Any("error", err))
+	}
+}
+
+// cachedEventRepository reads events by ID and the list of all events through the cache.
+// Lookups by user are personal and cheap, so they go straight to the database.
+type cachedEventRepository struct {
+	EventRepository
+	cache *EventCache
+}
+
+func NewCachedEventRepository(repository EventRepository, cache *EventCache) EventRepository {
+	return &cachedEventRepository{repository, cache}
+}
+
+func (repo *cachedEventRepository) FindAll(ctx context.Context) ([]Event, error) {
+	var events []Event
+	found, generation := repo.cache.get(ctx, allEventsCacheKey, &events)
+	if found {
+		return events, nil
+	}
+
+	events, err := repo.EventRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.set(ctx, allEventsCacheKey, generation, events)
+	return events, nil
+}
+
+func (repo *cachedEventRepository) FindOneByID(ctx context.Context, eventID string) (*Event, error) {
+	var event Event
+	found, generation := repo.cache.get(ctx, eventCacheKeyPrefix+eventID, &event)
+	if found {
+		return &event, nil
+	}
+
+	existingEvent, err := repo.EventRepository.FindOneByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.cache.set(ctx, eventCacheKeyPrefix+eventID, generation, existingEvent)
+	return existingEvent, nil
+}
+
+func (repo *cachedEventRepository) Create(ctx context.Context, event Event) error {
+	err := repo.EventRepository.Create(ctx, event)
+	repo.cache.invalidate(ctx, "")
+	return err
+}
+
+// Update invalidates the event even when the update fails, since a version conflict can
+// mean the cached copy is the outdated one.
+func (repo *cachedEventRepository) Update(ctx context.Context, event Event) error {
+	err := repo.EventRepository.Update(ctx, event)
+	repo.cache.invalidate(ctx, event.ID)
+	return err
+}
+
+func (repo *cachedEventRepository) Delete(ctx context.Context, eventID string) error {
+	err := repo.EventRepository.Delete(ctx, eventID)
+	repo.cache.invalidate(ctx, eventID)
+	return err
+}
+
+// cachedEventMemberRepository invalidates an event when transferring its ownership, which
+// writes the event alongside its members.
+type cachedEventMemberRepository struct {
+	EventMemberRepository
+	cache *EventCache
+}
+
+func NewCachedEventMemberRepository(repository EventMemberRepository, cache *EventCache) EventMemberRepository {
+	return &cachedEventMemberRepository{repository, cache}
+}
+
+func (repo *cachedEventMemberRepository) TransferOwnership(ctx context.Context, event Event, newOwner EventMember, previousOwner EventMember) error {
+	err := repo.EventMemberRepository.TransferOwnership(ctx, event, newOwner, previousOwner)
+	repo.cache.invalidate(ctx, event.ID)
+	return err
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryEventRepository stands in for the database. beforeRead, when set, runs after a
+// read has loaded its copy of the event and before it returns it.
+type memoryEventRepository struct {
+	EventRepository
+
+	mu         sync.Mutex
+	events     map[string]Event
+	reads      int
+	beforeRead func()
+}
+
+func (repo *memoryEventRepository) FindOneByID(ctx context.Context, eventID string) (*Event, error) {
+	repo.mu.Lock()
+	event := repo.events[eventID]
+	repo.reads++
+	beforeRead := repo.beforeRead
+	repo.beforeRead = nil
+	repo.mu.Unlock()
+
+	if beforeRead != nil {
+		beforeRead()
+	}
+	return &event, nil
+}
+
+func (repo *memoryEventRepository) Update(ctx context.Context, event Event) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.events[event.ID] = event
+	return nil
+}
+
+func newTestCachedEventRepository(t *testing.T, events ...Event) (EventRepository, *memoryEventRepository) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	database := &memoryEventRepository{events: make(map[string]Event)}
+	for _, event := range events {
+		database.events[event.ID] = event
+	}
+	cache := NewEventCache(client, slog.New(slog.DiscardHandler))
+	return NewCachedEventRepository(database, cache), database
+}
+
+func TestCachedEventRepositoryServesReadsFromCacheUntilUpdate(t *testing.T) {
+	ctx := context.Background()
+	repository, database := newTestCachedEventRepository(t, Event{ID: "e1", Name: "Launch", Version: 1})
+
+	for i := 0; i < 2; i++ {
+		if _, err := repository.FindOneByID(ctx, "e1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if database.reads != 1 {
+		t.Fatalf("got %d database reads, want 1", database.reads)
+	}
+
+	if err := repository.Update(ctx, Event{ID: "e1", Name: "Relaunch", Version: 2}); err != nil {
+		t.Fatal(err)
+	}
+	event, err := repository.FindOneByID(ctx, "e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != "Relaunch" || database.reads != 2 {
+		t.Fatalf("after the update: got %q with %d database reads, want Relaunch with 2", event.Name, database.reads)
+	}
+}
+
+func TestCachedEventRepositoryDropsFillRacingUpdate(t *testing.T) {
+	ctx := context.Background()
+	repository, database := newTestCachedEventRepository(t, Event{ID: "e1", Name: "Launch", Version: 1})
+
+	// The update commits and invalidates the cache after the read has loaded the old
+	// version, but before the read fills the cache with it.
+	database.beforeRead = func() {
+		if err := repository.Update(ctx, Event{ID: "e1", Name: "Relaunch", Version: 2}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	stale, err := repository.FindOneByID(ctx, "e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.Version != 1 {
+		t.Fatalf("racing read: got version %d, want the version it loaded, 1", stale.Version)
+	}
+
+	event, err := repository.FindOneByID(ctx, "e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 2 {
+		t.Fatalf("read after the update: got version %d from the cache, want 2", event.Version)
+	}
+}
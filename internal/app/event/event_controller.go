@@ -2,128 +2,334 @@ package event
 
 import (
 	"net/http"
+	"time"
 
 	EventDTO "github.com/edwinedjokpa/event-booking-api/internal/app/event/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
 	APIResponse "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/apiresponse"
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/validator"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
+// publicCacheControl lets browsers and CDNs reuse the public event reads for a minute, and
+// then revalidate them with the ETag. The ETag only identifies the event data, so these
+// responses carry no translated message, which would make them differ by locale.
+const publicCacheControl = "public, max-age=60, stale-while-revalidate=30"
+
 type EventController interface {
 	CreateEvent(c *gin.Context)
 	GetAllEvents(c *gin.Context)
 	GetEventByID(c *gin.Context)
 	UpdateEvent(c *gin.Context)
 	DeleteEvent(c *gin.Context)
+	GetEventMembers(c *gin.Context)
+	InviteMember(c *gin.Context)
+	UpdateMemberRole(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	TransferOwnership(c *gin.Context)
+	GetInvitations(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+	DeclineInvitation(c *gin.Context)
 }
 
 type eventController struct {
 	service   EventService
-	validator *validator.Validate
+	validator *validator.Validator
 }
 
-func NewEventController(service EventService, validator *validator.Validate) EventController {
+func NewEventController(service EventService, validator *validator.Validator) EventController {
 	return &eventController{service, validator}
 }
 
 func (ctrl *eventController) CreateEvent(ctx *gin.Context) {
 	userIDRaw, exists := ctx.Get("userID")
 	if !exists {
-		exception := HTTPException.NewUnauthorizedException("Unauthorized", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(middleware.ErrUnauthenticated)
 		return
 	}
 
 	userID, ok := userIDRaw.(string)
 	if !ok {
-		exception := HTTPException.NewBadRequestException("User ID not found in context", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(middleware.ErrUnauthenticated)
 		return
 	}
 
 	var request EventDTO.CreateEventRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.service.CreateEvent(ctx, userID, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.CreateEvent(userID, request)
-	ctx.JSON(http.StatusCreated, APIResponse.Success("Event created successfully", nil))
+	ctx.JSON(http.StatusCreated, APIResponse.Success(i18n.T(ctx, "event.created"), nil))
 }
 
 func (ctrl *eventController) GetAllEvents(ctx *gin.Context) {
-	allEvents := ctrl.service.GetAllEvents()
-	ctx.JSON(http.StatusOK, APIResponse.Success("All events retrieved successfully", gin.H{"events": allEvents}))
+	allEvents, err := ctrl.service.GetAllEvents(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// The list has no Last-Modified: removing an event changes it without changing when
+	// any of the remaining events was last modified.
+	etag := ListETag(allEvents)
+	ctx.Header("Cache-Control", publicCacheControl)
+	ctx.Header("ETag", etag)
+	if util.IsNotModified(ctx.Request.Header, etag, time.Time{}) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success("", gin.H{"events": allEvents}))
 }
 
 func (ctrl *eventController) GetEventByID(ctx *gin.Context) {
 	eventID := ctx.Param("id")
 
-	event := ctrl.service.GetEventByID(eventID)
-	ctx.JSON(http.StatusOK, APIResponse.Success("Event retrieved successfully", gin.H{"event": event}))
+	event, err := ctrl.service.GetEventByID(ctx, eventID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Cache-Control", publicCacheControl)
+	ctx.Header("ETag", event.ETag())
+	ctx.Header("Last-Modified", event.UpdatedAt.UTC().Format(http.TimeFormat))
+	if util.IsNotModified(ctx.Request.Header, event.ETag(), event.UpdatedAt) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success("", gin.H{"event": event}))
 }
 
 func (ctrl *eventController) UpdateEvent(ctx *gin.Context) {
 	eventID := ctx.Param("id")
 
-	userIDRaw, exists := ctx.Get("userID")
-	if !exists {
-		exception := HTTPException.NewUnauthorizedException("Unauthorized", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
 		return
 	}
 
-	userID, ok := userIDRaw.(string)
-	if !ok {
-		exception := HTTPException.NewBadRequestException("User ID not found in context", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	var request EventDTO.UpdateEventRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
 		return
 	}
 
-	var request EventDTO.UpdateEventRequest
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		exception := HTTPException.NewBadRequestException("Bad Request Exception", err.Error())
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := ctrl.validator.Struct(request); err != nil {
-		exception := util.FormatValidationErrors(err)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	event, err := ctrl.service.UpdateEvent(ctx, principal, eventID, ctx.GetHeader("If-Match"), request)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.UpdateEvent(userID, eventID, request)
-	ctx.JSON(http.StatusOK, APIResponse.Success("Event updated successfully", nil))
+	ctx.Header("ETag", event.ETag())
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.updated"), nil))
 }
 
 func (ctrl *eventController) DeleteEvent(ctx *gin.Context) {
 	eventID := ctx.Param("id")
 
-	userIDRaw, exists := ctx.Get("userID")
-	if !exists {
-		exception := HTTPException.NewUnauthorizedException("Unauthorized", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
 		return
 	}
 
-	userID, ok := userIDRaw.(string)
-	if !ok {
-		exception := HTTPException.NewBadRequestException("User ID not found in context", nil)
-		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	if err := ctrl.service.DeleteEvent(ctx, principal, eventID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.deleted"), nil))
+}
+
+func (ctrl *eventController) GetEventMembers(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	members, err := ctrl.service.GetEventMembers(ctx, principal, eventID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.members_retrieved"), gin.H{"members": members}))
+}
+
+func (ctrl *eventController) InviteMember(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	var request EventDTO.InviteMemberRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := ctrl.service.InviteMember(ctx, principal, eventID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, APIResponse.Success(i18n.T(ctx, "event.invitation_sent"), nil))
+}
+
+func (ctrl *eventController) UpdateMemberRole(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	memberID := ctx.Param("memberId")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	var request EventDTO.UpdateMemberRoleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := ctrl.service.UpdateMemberRole(ctx, principal, eventID, memberID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.member_role_updated"), nil))
+}
+
+func (ctrl *eventController) RemoveMember(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+	memberID := ctx.Param("memberId")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.RemoveMember(ctx, principal, eventID, memberID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.member_removed"), nil))
+}
+
+func (ctrl *eventController) TransferOwnership(ctx *gin.Context) {
+	eventID := ctx.Param("id")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	var request EventDTO.TransferOwnershipRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := ctrl.service.TransferOwnership(ctx, principal, eventID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.ownership_transferred"), nil))
+}
+
+func (ctrl *eventController) GetInvitations(ctx *gin.Context) {
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	invitations, err := ctrl.service.GetInvitations(ctx, principal)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.invitations_retrieved"), gin.H{"invitations": invitations}))
+}
+
+func (ctrl *eventController) AcceptInvitation(ctx *gin.Context) {
+	memberID := ctx.Param("id")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.RespondToInvitation(ctx, principal, memberID, true); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.invitation_accepted"), nil))
+}
+
+func (ctrl *eventController) DeclineInvitation(ctx *gin.Context) {
+	memberID := ctx.Param("id")
+
+	principal := middleware.GetPrincipal(ctx)
+	if principal.UserID == "" {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return
+	}
+
+	if err := ctrl.service.RespondToInvitation(ctx, principal, memberID, false); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctrl.service.DeleteEvent(userID, eventID)
-	ctx.JSON(http.StatusOK, APIResponse.Success("Event deleted successfully", nil))
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "event.invitation_declined"), nil))
 }
@@ -0,0 +1,61 @@
+package event
+
+import "time"
+
+type MemberRole string
+
+const (
+	MemberRoleCoOwner      MemberRole = "co_owner"
+	MemberRoleEditor       MemberRole = "editor"
+	MemberRoleCheckInStaff MemberRole = "checkin_staff"
+	MemberRoleViewer       MemberRole = "viewer"
+)
+
+type MemberStatus string
+
+const (
+	MemberStatusPending  MemberStatus = "pending"
+	MemberStatusAccepted MemberStatus = "accepted"
+	MemberStatusDeclined MemberStatus = "declined"
+)
+
+// EventAction is something a user may be allowed to do to an event they don't own.
+type EventAction string
+
+const (
+	EventActionView          EventAction = "view"
+	EventActionUpdate        EventAction = "update"
+	EventActionDelete        EventAction = "delete"
+	EventActionCheckIn       EventAction = "check_in"
+	EventActionManageMembers EventAction = "manage_members"
+)
+
+var memberRoleActions = map[MemberRole][]EventAction{
+	MemberRoleCoOwner:      {EventActionView, EventActionUpdate, EventActionCheckIn, EventActionManageMembers},
+	MemberRoleEditor:       {EventActionView, EventActionUpdate, EventActionCheckIn},
+	MemberRoleCheckInStaff: {EventActionView, EventActionCheckIn},
+	MemberRoleViewer:       {EventActionView},
+}
+
+func (r MemberRole) Can(action EventAction) bool {
+	for _, allowed := range memberRoleActions[r] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// EventMember grants a user other than the owner a role on an event. Members are
+// invited by email and only gain access once they accept.
+type EventMember struct {
+	ID        string       `gorm:"primaryKey;not null" json:"id"`
+	EventID   string       `gorm:"not null;uniqueIndex:idx_event_members_event_email" json:"event_id"`
+	Email     string       `gorm:"not null;uniqueIndex:idx_event_members_event_email" json:"email"`
+	UserID    *string      `gorm:"index" json:"user_id,omitempty"`
+	Role      MemberRole   `gorm:"type:varchar(20);not null" json:"role"`
+	Status    MemberStatus `gorm:"type:varchar(20);not null" json:"status"`
+	InvitedBy string       `gorm:"not null" json:"invited_by"`
+	CreatedAt time.Time    `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,151 @@
+package event
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type EventMemberRepository interface {
+	Create(ctx context.Context, member EventMember) error
+	FindOneByID(ctx context.Context, memberID string) (*EventMember, error)
+	FindAllByEventID(ctx context.Context, eventID string) ([]EventMember, error)
+	FindOneAcceptedByEventAndUser(ctx context.Context, eventID, userID string) (*EventMember, error)
+	FindOneByEventAndEmail(ctx context.Context, eventID, email string) (*EventMember, error)
+	FindAllPendingByEmail(ctx context.Context, email string) ([]EventMember, error)
+	FindAllByUser(ctx context.Context, userID, email string) ([]EventMember, error)
+	DeleteAllByUser(ctx context.Context, userID, email string) error
+	Update(ctx context.Context, member EventMember) error
+	Delete(ctx context.Context, memberID string) error
+	TransferOwnership(ctx context.Context, event Event, newOwner EventMember, previousOwner EventMember) error
+}
+
+type eventMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewEventMemberRepository(db *gorm.DB) EventMemberRepository {
+	return &eventMemberRepository{db}
+}
+
+func (repo *eventMemberRepository) Create(ctx context.Context, member EventMember) error {
+	if err := repo.db.WithContext(ctx).Create(&member).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *eventMemberRepository) FindOneByID(ctx context.Context, memberID string) (*EventMember, error) {
+	var member EventMember
+	if err := repo.db.WithContext(ctx).First(&member, "id = ?", memberID).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (repo *eventMemberRepository) FindAllByEventID(ctx context.Context, eventID string) ([]EventMember, error) {
+	var members []EventMember
+	if err := repo.db.WithContext(ctx).Where("event_id = ?", eventID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (repo *eventMemberRepository) FindOneAcceptedByEventAndUser(ctx context.Context, eventID, userID string) (*EventMember, error) {
+	var member EventMember
+	err := repo.db.WithContext(ctx).
+		Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, MemberStatusAccepted).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (repo *eventMemberRepository) FindOneByEventAndEmail(ctx context.Context, eventID, email string) (*EventMember, error) {
+	var member EventMember
+	if err := repo.db.WithContext(ctx).Where("event_id = ? AND email = ?", eventID, email).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (repo *eventMemberRepository) FindAllPendingByEmail(ctx context.Context, email string) ([]EventMember, error) {
+	var members []EventMember
+	err := repo.db.WithContext(ctx).
+		Where("email = ? AND status = ?", email, MemberStatusPending).
+		Order("created_at").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// FindAllByUser returns every membership and invitation held by the user, matched by
+// user ID once accepted and by email before that.
+func (repo *eventMemberRepository) FindAllByUser(ctx context.Context, userID, email string) ([]EventMember, error) {
+	var members []EventMember
+	if err := repo.db.WithContext(ctx).Where("user_id = ? OR email = ?", userID, email).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (repo *eventMemberRepository) DeleteAllByUser(ctx context.Context, userID, email string) error {
+	if err := repo.db.WithContext(ctx).Where("user_id = ? OR email = ?", userID, email).Delete(&EventMember{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *eventMemberRepository) Update(ctx context.Context, member EventMember) error {
+	if err := repo.db.WithContext(ctx).Save(&member).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *eventMemberRepository) Delete(ctx context.Context, memberID string) error {
+	if err := repo.db.WithContext(ctx).Delete(&EventMember{}, "id = ?", memberID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// TransferOwnership makes newOwner the event's owner and records the previous owner as a
+// co-owner, atomically. Only the owner is written, so concurrent edits to the event's
+// details are kept, and its version is incremented. It returns gorm.ErrRecordNotFound when
+// the event is no longer owned by previousOwner, or newOwner is no longer an accepted
+// member, so that of two concurrent transfers only one succeeds.
+func (repo *eventMemberRepository) TransferOwnership(ctx context.Context, event Event, newOwner EventMember, previousOwner EventMember) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Event{}).
+			Where("id = ? AND user_id = ?", event.ID, *previousOwner.UserID).
+			Updates(map[string]interface{}{
+				"user_id":    event.UserID,
+				"updated_at": event.UpdatedAt,
+				"version":    gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		result = tx.Where("id = ? AND user_id = ? AND status = ?", newOwner.ID, event.UserID, MemberStatusAccepted).Delete(&EventMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("event_id = ? AND email = ?", previousOwner.EventID, previousOwner.Email).Delete(&EventMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&previousOwner).Error
+	})
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"net/http"
+
+	EventDTO "github.com/edwinedjokpa/event-booking-api/internal/app/event/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
+)
+
+var ifNoneMatchHeader = openapi.Parameter{In: "header", Name: "If-None-Match", Description: "The ETag of a cached copy"}
+
+var idempotencyKeyHeader = openapi.Parameter{
+	In:          "header",
+	Name:        middleware.IdempotencyKeyHeader,
+	Description: "Makes retries safe: the first successful response to a key is replayed for 24 hours, with an Idempotent-Replayed header",
+}
+
+// manageEventsDescription is added to the routes that change an event.
+const manageEventsDescription = "API keys need the events:manage scope."
+
+// invitationsDescription explains why invitation requests fail with 403 for some callers.
+const invitationsDescription = "Invitations are addressed to an email, so this fails with 403 until the caller has verified theirs " +
+	"by signing in with a code or link sent to it, or by resetting their password."
+
+// OpenAPIOperations documents the routes registered by RegisterRoutes.
+func OpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{
+			Method: http.MethodGet, Path: "/events", Summary: "List events",
+			Description: "Cacheable for a minute. Returns 304 Not Modified when If-None-Match has the current ETag.",
+			Parameters:  []openapi.Parameter{ifNoneMatchHeader},
+			Data:        openapi.Data{"events": []Event{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/events/:id", Summary: "Get an event",
+			Description: "Cacheable for a minute. The ETag header identifies the event's version, for If-Match on updates. " +
+				"Returns 304 Not Modified when If-None-Match has the current ETag, or the event has not changed since If-Modified-Since.",
+			Parameters: []openapi.Parameter{ifNoneMatchHeader, {In: "header", Name: "If-Modified-Since", Description: "The Last-Modified date of a cached copy"}},
+			Data:       openapi.Data{"event": Event{}},
+		},
+		{Method: http.MethodPost, Path: "/events/", Summary: "Create an event", Security: openapi.SecurityAny, Parameters: []openapi.Parameter{idempotencyKeyHeader}, Body: EventDTO.CreateEventRequest{}, Status: http.StatusCreated},
+		{
+			Method: http.MethodPut, Path: "/events/:id", Summary: "Update an event", Security: openapi.SecurityAny,
+			Description: "Fails with 428 without If-Match, and with 412 when the event has changed since the ETag was retrieved. The new ETag is returned. " +
+				manageEventsDescription,
+			Parameters: []openapi.Parameter{{In: "header", Name: "If-Match", Description: "The ETag from getting the event", Required: true}},
+			Body:       EventDTO.UpdateEventRequest{},
+		},
+		{Method: http.MethodDelete, Path: "/events/:id", Summary: "Delete an event", Security: openapi.SecurityAny, Description: manageEventsDescription},
+
+		{Method: http.MethodGet, Path: "/events/:id/members", Summary: "List an event's members", Security: openapi.SecurityAny, Data: openapi.Data{"members": []EventMember{}}},
+		{Method: http.MethodPost, Path: "/events/:id/members", Summary: "Invite a member", Security: openapi.SecurityAny, Description: manageEventsDescription, Parameters: []openapi.Parameter{idempotencyKeyHeader}, Body: EventDTO.InviteMemberRequest{}, Status: http.StatusCreated},
+		{Method: http.MethodPatch, Path: "/events/:id/members/:memberId", Summary: "Change a member's role", Security: openapi.SecurityAny, Description: manageEventsDescription, Body: EventDTO.UpdateMemberRoleRequest{}},
+		{Method: http.MethodDelete, Path: "/events/:id/members/:memberId", Summary: "Remove a member", Security: openapi.SecurityAny, Description: manageEventsDescription},
+		{Method: http.MethodPost, Path: "/events/:id/transfer", Summary: "Transfer ownership to a member", Security: openapi.SecurityAny, Description: manageEventsDescription, Body: EventDTO.TransferOwnershipRequest{}},
+
+		{
+			Method: http.MethodGet, Path: "/events/invitations", Summary: "List the caller's pending invitations", Security: openapi.SecuritySession,
+			Description: invitationsDescription, Data: openapi.Data{"invitations": []EventMember{}},
+		},
+		{Method: http.MethodPost, Path: "/events/invitations/:id/accept", Summary: "Accept an invitation", Security: openapi.SecuritySession, Description: invitationsDescription},
+		{Method: http.MethodPost, Path: "/events/invitations/:id/decline", Summary: "Decline an invitation", Security: openapi.SecuritySession, Description: invitationsDescription},
+	}
+}
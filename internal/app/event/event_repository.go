@@ -1,15 +1,19 @@
 package event
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 )
 
 type EventRepository interface {
-	Create(event Event) error
-	FindAll() ([]Event, error)
-	FindOneByID(eventID string) (*Event, error)
-	Update(event Event) error
-	Delete(eventID string) error
+	Create(ctx context.Context, event Event) error
+	FindAll(ctx context.Context) ([]Event, error)
+	FindOneByID(ctx context.Context, eventID string) (*Event, error)
+	FindAllByUserID(ctx context.Context, userID string) ([]Event, error)
+	FindAllManagedByUserID(ctx context.Context, userID string) ([]Event, error)
+	Update(ctx context.Context, event Event) error
+	Delete(ctx context.Context, eventID string) error
 }
 
 type eventRepository struct {
@@ -20,39 +24,82 @@ func NewEventRepository(db *gorm.DB) EventRepository {
 	return &eventRepository{db}
 }
 
-func (repo *eventRepository) Create(event Event) error {
-	if err := repo.db.Create(&event).Error; err != nil {
+func (repo *eventRepository) Create(ctx context.Context, event Event) error {
+	if err := repo.db.WithContext(ctx).Create(&event).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (repo *eventRepository) FindAll() ([]Event, error) {
+func (repo *eventRepository) FindAll(ctx context.Context) ([]Event, error) {
 	var events []Event
-	if err := repo.db.Find(&events).Error; err != nil {
+	// A stable order keeps the list's ETag stable while the events do not change.
+	if err := repo.db.WithContext(ctx).Order("created_at, id").Find(&events).Error; err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (repo *eventRepository) FindOneByID(eventID string) (*Event, error) {
+func (repo *eventRepository) FindOneByID(ctx context.Context, eventID string) (*Event, error) {
 	var event Event
-	if err := repo.db.First(&event, "id = ?", eventID).Error; err != nil {
+	if err := repo.db.WithContext(ctx).First(&event, "id = ?", eventID).Error; err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
 
-func (repo *eventRepository) Update(event Event) error {
-	if err := repo.db.Save(&event).Error; err != nil {
-		return err
+func (repo *eventRepository) FindAllByUserID(ctx context.Context, userID string) ([]Event, error) {
+	var events []Event
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Order("date").Find(&events).Error; err != nil {
+		return nil, err
 	}
-	return nil
+	return events, nil
 }
 
-func (repo *eventRepository) Delete(eventID string) error {
-	if err := repo.db.Delete(&Event{}, "id = ?", eventID).Error; err != nil {
-		return err
+// FindAllManagedByUserID returns the events the user has an accepted membership on.
+func (repo *eventRepository) FindAllManagedByUserID(ctx context.Context, userID string) ([]Event, error) {
+	var events []Event
+	err := repo.db.WithContext(ctx).
+		Joins("JOIN event_members ON event_members.event_id = events.id").
+		Where("event_members.user_id = ? AND event_members.status = ?", userID, MemberStatusAccepted).
+		Order("events.date").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
 	}
+	return events, nil
+}
+
+// Update saves the event if it is still at event.Version, and increments its version. It
+// returns gorm.ErrRecordNotFound when the event has been changed or deleted since it was read.
+func (repo *eventRepository) Update(ctx context.Context, event Event) error {
+	result := repo.db.WithContext(ctx).Model(&Event{}).
+		Where("id = ? AND version = ?", event.ID, event.Version).
+		Updates(map[string]interface{}{
+			"name":        event.Name,
+			"description": event.Description,
+			"location":    event.Location,
+			"date":        event.Date,
+			"updated_at":  event.UpdatedAt,
+			"version":     gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
 	return nil
 }
+
+func (repo *eventRepository) Delete(ctx context.Context, eventID string) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&EventMember{}, "event_id = ?", eventID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Event{}, "id = ?", eventID).Error
+	})
+}
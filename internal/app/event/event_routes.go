@@ -2,19 +2,35 @@ package event
 
 import (
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, controller EventController, jwtSecretKey []byte) {
+// RegisterRoutes registers the event routes. Creating an event and inviting a member accept
+// an Idempotency-Key, see middleware.IdempotencyMiddleware. API keys need the events:manage
+// scope to change an event, and cannot act on invitations, which belong to the user in person.
+func RegisterRoutes(router *gin.RouterGroup, controller EventController, authMiddleware, idempotencyMiddleware gin.HandlerFunc) {
 	router.GET("/events", controller.GetAllEvents)
 	router.GET("/events/:id", controller.GetEventByID)
 
+	manageEvents := middleware.RequireScope(rbac.PermissionManageEvents)
+
 	authRouter := router.Group("/events")
-	authRouter.Use(middleware.AuthMiddleware(jwtSecretKey))
+	authRouter.Use(authMiddleware)
 	{
-		authRouter.POST("/", controller.CreateEvent)
-		authRouter.PUT("/:id", controller.UpdateEvent)
-		authRouter.DELETE("/:id", controller.DeleteEvent)
+		authRouter.POST("/", middleware.RequirePermission(rbac.PermissionCreateEvents), idempotencyMiddleware, controller.CreateEvent)
+		authRouter.PUT("/:id", manageEvents, controller.UpdateEvent)
+		authRouter.DELETE("/:id", manageEvents, controller.DeleteEvent)
+
+		authRouter.GET("/:id/members", controller.GetEventMembers)
+		authRouter.POST("/:id/members", manageEvents, idempotencyMiddleware, controller.InviteMember)
+		authRouter.PATCH("/:id/members/:memberId", manageEvents, controller.UpdateMemberRole)
+		authRouter.DELETE("/:id/members/:memberId", manageEvents, controller.RemoveMember)
+		authRouter.POST("/:id/transfer", manageEvents, controller.TransferOwnership)
+
+		authRouter.GET("/invitations", middleware.RequireSession(), controller.GetInvitations)
+		authRouter.POST("/invitations/:id/accept", middleware.RequireSession(), controller.AcceptInvitation)
+		authRouter.POST("/invitations/:id/decline", middleware.RequireSession(), controller.DeclineInvitation)
 	}
 }
@@ -1,34 +1,78 @@
 package event
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	EventDTO "github.com/edwinedjokpa/event-booking-api/internal/app/event/dto"
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/metrics"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrEventNotFound      = apperror.NotFound("event_not_found", "Event not found")
+	ErrMemberNotFound     = apperror.NotFound("event_member_not_found", "Member not found")
+	ErrInvitationNotFound = apperror.NotFound("invitation_not_found", "Invitation not found")
+	// ErrEmailUnverified is returned for invitations, which are addressed to an email, until
+	// the caller has proved they receive mail at theirs.
+	ErrEmailUnverified = apperror.Forbidden("email_unverified", "Verify your email address by signing in with a code sent to it to see your invitations")
+
+	ErrUpdateForbidden        = apperror.Forbidden("event_update_forbidden", "You do not have permission to update this event")
+	ErrDeleteForbidden        = apperror.Forbidden("event_delete_forbidden", "You do not have permission to delete this event")
+	ErrViewMembersForbidden   = apperror.Forbidden("event_members_view_forbidden", "You do not have permission to view this event's members")
+	ErrManageMembersForbidden = apperror.Forbidden("event_members_manage_forbidden", "You do not have permission to manage this event's members")
+	ErrCoOwnerForbidden       = apperror.Forbidden("co_owner_change_forbidden", "Only the event owner can add, change or remove co-owners")
+	ErrTransferForbidden      = apperror.Forbidden("ownership_transfer_forbidden", "Only the event owner can transfer ownership")
+
+	ErrAlreadyOwner        = apperror.Validation("already_event_owner", "You already own this event")
+	ErrAlreadyInvited      = apperror.Conflict("member_already_invited", "User has already been invited to this event")
+	ErrTransferToNonMember = apperror.Validation("ownership_transfer_not_accepted", "Ownership can only be transferred to a member who has accepted their invitation")
+	ErrTransferConflict    = apperror.Conflict("ownership_transfer_conflict", "The event's owner or the member changed during the transfer, please try again")
+
+	ErrIfMatchRequired = apperror.PreconditionRequired("if_match_required", "If-Match header with the event's ETag is required")
+	ErrEventModified   = apperror.PreconditionFailed("event_modified", "Event has been changed since it was retrieved")
+)
+
 type EventService interface {
-	CreateEvent(userID string, request EventDTO.CreateEventRequest)
-	GetAllEvents() []Event
-	GetEventByID(eventID string) *Event
-	UpdateEvent(userID, eventID string, request EventDTO.UpdateEventRequest)
-	DeleteEvent(userID, eventID string)
+	CreateEvent(ctx context.Context, userID string, request EventDTO.CreateEventRequest) error
+	GetAllEvents(ctx context.Context) ([]Event, error)
+	GetEventByID(ctx context.Context, eventID string) (*Event, error)
+	UpdateEvent(ctx context.Context, principal rbac.Principal, eventID, ifMatch string, request EventDTO.UpdateEventRequest) (*Event, error)
+	DeleteEvent(ctx context.Context, principal rbac.Principal, eventID string) error
+	GetEventMembers(ctx context.Context, principal rbac.Principal, eventID string) ([]EventMember, error)
+	InviteMember(ctx context.Context, principal rbac.Principal, eventID string, request EventDTO.InviteMemberRequest) error
+	UpdateMemberRole(ctx context.Context, principal rbac.Principal, eventID, memberID string, request EventDTO.UpdateMemberRoleRequest) error
+	RemoveMember(ctx context.Context, principal rbac.Principal, eventID, memberID string) error
+	TransferOwnership(ctx context.Context, principal rbac.Principal, eventID string, request EventDTO.TransferOwnershipRequest) error
+	GetInvitations(ctx context.Context, principal rbac.Principal) ([]EventMember, error)
+	RespondToInvitation(ctx context.Context, principal rbac.Principal, memberID string, accept bool) error
 }
 
 type eventService struct {
-	repository EventRepository
+	repository         EventRepository
+	uncachedRepository EventRepository
+	memberRepository   EventMemberRepository
+	logger             *slog.Logger
 }
 
-func NewEventService(repository EventRepository) EventService {
-	return &eventService{repository}
+// NewEventService serves reads through repository, which may be cached, and writes through
+// it so that they invalidate the cache. Events are checked against uncachedRepository
+// before they are changed, since a cached copy can predate a change of owner or version.
+func NewEventService(repository, uncachedRepository EventRepository, memberRepository EventMemberRepository, logger *slog.Logger) EventService {
+	return &eventService{repository, uncachedRepository, memberRepository, logger}
 }
 
-func (svc *eventService) CreateEvent(userID string, request EventDTO.CreateEventRequest) {
+func (svc *eventService) CreateEvent(ctx context.Context, userID string, request EventDTO.CreateEventRequest) error {
 	event := Event{
 		ID:          util.GenerateUUID(),
 		Name:        request.Name,
@@ -40,38 +84,62 @@ func (svc *eventService) CreateEvent(userID string, request EventDTO.CreateEvent
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := svc.repository.Create(event); err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to create event", nil))
+	if err := svc.repository.Create(ctx, event); err != nil {
+		return fmt.Errorf("creating event: %w", err)
 	}
+
+	metrics.EventsCreatedTotal.Inc()
+	return nil
 }
 
-func (svc *eventService) GetAllEvents() []Event {
-	allEvents, err := svc.repository.FindAll()
+func (svc *eventService) GetAllEvents(ctx context.Context) ([]Event, error) {
+	allEvents, err := svc.repository.FindAll(ctx)
 	if err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to retrieve all events", nil))
+		return nil, fmt.Errorf("finding events: %w", err)
 	}
 
-	return allEvents
+	return allEvents, nil
 }
 
-func (svc *eventService) GetEventByID(eventID string) *Event {
-	event, err := svc.repository.FindOneByID(eventID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		panic(err)
-	}
+func (svc *eventService) GetEventByID(ctx context.Context, eventID string) (*Event, error) {
+	return findEvent(ctx, svc.repository, eventID)
+}
 
-	if event == nil || errors.Is(err, gorm.ErrRecordNotFound) {
-		panic(HTTPException.NewNotFoundException(fmt.Sprintf("Event with ID %s not found", eventID), nil))
+// getCurrentEvent reads the event from the database, for checks made before changing it.
+func (svc *eventService) getCurrentEvent(ctx context.Context, eventID string) (*Event, error) {
+	return findEvent(ctx, svc.uncachedRepository, eventID)
+}
+
+func findEvent(ctx context.Context, repository EventRepository, eventID string) (*Event, error) {
+	event, err := repository.FindOneByID(ctx, eventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && event == nil) {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding event: %w", err)
 	}
 
-	return event
+	return event, nil
 }
 
-func (svc *eventService) UpdateEvent(userID, eventID string, request EventDTO.UpdateEventRequest) {
-	existingEvent := svc.GetEventByID(eventID)
+// UpdateEvent applies the request to the event if ifMatch, an If-Match header value, matches
+// its ETag. It returns the updated event.
+func (svc *eventService) UpdateEvent(ctx context.Context, principal rbac.Principal, eventID, ifMatch string, request EventDTO.UpdateEventRequest) (*Event, error) {
+	existingEvent, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.authorize(ctx, principal, existingEvent, EventActionUpdate, ErrUpdateForbidden); err != nil {
+		return nil, err
+	}
 
-	if existingEvent.UserID != userID {
-		panic(HTTPException.NewUnauthorizedException("You cannot update an event that was not created by you", nil))
+	if ifMatch == "" {
+		return nil, ErrIfMatchRequired
+	}
+
+	if !util.MatchesETag(ifMatch, existingEvent.ETag()) {
+		return nil, ErrEventModified
 	}
 
 	if request.Name != nil {
@@ -92,19 +160,297 @@ func (svc *eventService) UpdateEvent(userID, eventID string, request EventDTO.Up
 
 	existingEvent.UpdatedAt = time.Now()
 
-	if err := svc.repository.Update(*existingEvent); err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to update event", err.Error()))
+	// The update only applies if nobody else has updated the event since it was read above.
+	err = svc.repository.Update(ctx, *existingEvent)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEventModified
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating event: %w", err)
+	}
+
+	existingEvent.Version++
+	return existingEvent, nil
+}
+
+func (svc *eventService) DeleteEvent(ctx context.Context, principal rbac.Principal, eventID string) error {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.authorize(ctx, principal, event, EventActionDelete, ErrDeleteForbidden); err != nil {
+		return err
+	}
+
+	if err := svc.repository.Delete(ctx, eventID); err != nil {
+		return fmt.Errorf("deleting event: %w", err)
+	}
+
+	metrics.EventsDeletedTotal.Inc()
+	return nil
+}
+
+func (svc *eventService) GetEventMembers(ctx context.Context, principal rbac.Principal, eventID string) ([]EventMember, error) {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.authorize(ctx, principal, event, EventActionView, ErrViewMembersForbidden); err != nil {
+		return nil, err
+	}
+
+	members, err := svc.memberRepository.FindAllByEventID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("finding event members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (svc *eventService) InviteMember(ctx context.Context, principal rbac.Principal, eventID string, request EventDTO.InviteMemberRequest) error {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	role := MemberRole(request.Role)
+
+	if err := svc.authorize(ctx, principal, event, EventActionManageMembers, ErrManageMembersForbidden); err != nil {
+		return err
+	}
+
+	if role == MemberRoleCoOwner && !svc.isOwnerOrAdmin(principal, event) {
+		return ErrCoOwnerForbidden
+	}
+
+	normalizedEmail := strings.ToLower(strings.TrimSpace(request.Email))
+	if normalizedEmail == strings.ToLower(principal.Email) && event.UserID == principal.UserID {
+		return ErrAlreadyOwner
+	}
+
+	existingMember, err := svc.memberRepository.FindOneByEventAndEmail(ctx, eventID, normalizedEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("finding event member: %w", err)
+	}
+
+	if existingMember != nil && existingMember.Status != MemberStatusDeclined {
+		return ErrAlreadyInvited
+	}
+
+	now := time.Now()
+	member := EventMember{
+		ID:        util.GenerateUUID(),
+		EventID:   eventID,
+		Email:     normalizedEmail,
+		Role:      role,
+		Status:    MemberStatusPending,
+		InvitedBy: principal.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if existingMember != nil {
+		member.ID = existingMember.ID
+		member.CreatedAt = existingMember.CreatedAt
+		err = svc.memberRepository.Update(ctx, member)
+	} else {
+		err = svc.memberRepository.Create(ctx, member)
+	}
+
+	if err != nil {
+		return fmt.Errorf("saving invitation: %w", err)
+	}
+
+	// The invitee may not have an account yet, so the email uses the inviter's locale.
+	locale := i18n.FromContext(ctx)
+	logging.FromContext(ctx, svc.logger).Info("Event invitation issued",
+		slog.String("email", member.Email),
+		slog.String("locale", locale),
+		slog.String("subject", i18n.Message(locale, "email.invitation.subject")),
+		slog.String("event_id", event.ID),
+		slog.String("role", string(member.Role)),
+		slog.String("invitation_id", member.ID),
+	)
+	return nil
+}
+
+func (svc *eventService) UpdateMemberRole(ctx context.Context, principal rbac.Principal, eventID, memberID string, request EventDTO.UpdateMemberRoleRequest) error {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	role := MemberRole(request.Role)
+
+	if err := svc.authorize(ctx, principal, event, EventActionManageMembers, ErrManageMembersForbidden); err != nil {
+		return err
+	}
+
+	member, err := svc.getEventMember(ctx, eventID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if (role == MemberRoleCoOwner || member.Role == MemberRoleCoOwner) && !svc.isOwnerOrAdmin(principal, event) {
+		return ErrCoOwnerForbidden
+	}
+
+	member.Role = role
+	member.UpdatedAt = time.Now()
+
+	if err := svc.memberRepository.Update(ctx, *member); err != nil {
+		return fmt.Errorf("updating member role: %w", err)
 	}
+	return nil
 }
 
-func (svc *eventService) DeleteEvent(userID, eventID string) {
-	event := svc.GetEventByID(eventID)
+func (svc *eventService) RemoveMember(ctx context.Context, principal rbac.Principal, eventID, memberID string) error {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	member, err := svc.getEventMember(ctx, eventID, memberID)
+	if err != nil {
+		return err
+	}
 
-	if event.UserID != userID {
-		panic(HTTPException.NewUnauthorizedException("You cannot delete an event that was not created by you", nil))
+	isSelf := member.UserID != nil && *member.UserID == principal.UserID
+	if !isSelf {
+		if err := svc.authorize(ctx, principal, event, EventActionManageMembers, ErrManageMembersForbidden); err != nil {
+			return err
+		}
+
+		if member.Role == MemberRoleCoOwner && !svc.isOwnerOrAdmin(principal, event) {
+			return ErrCoOwnerForbidden
+		}
+	}
+
+	if err := svc.memberRepository.Delete(ctx, member.ID); err != nil {
+		return fmt.Errorf("removing member: %w", err)
+	}
+	return nil
+}
+
+// TransferOwnership hands the event to an accepted member. The previous owner stays on as a co-owner.
+func (svc *eventService) TransferOwnership(ctx context.Context, principal rbac.Principal, eventID string, request EventDTO.TransferOwnershipRequest) error {
+	event, err := svc.getCurrentEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	if event.UserID != principal.UserID {
+		return ErrTransferForbidden
+	}
+
+	newOwner, err := svc.getEventMember(ctx, eventID, request.MemberID)
+	if err != nil {
+		return err
+	}
+	if newOwner.Status != MemberStatusAccepted || newOwner.UserID == nil {
+		return ErrTransferToNonMember
+	}
+
+	now := time.Now()
+	previousOwner := EventMember{
+		ID:        util.GenerateUUID(),
+		EventID:   eventID,
+		Email:     strings.ToLower(principal.Email),
+		UserID:    &principal.UserID,
+		Role:      MemberRoleCoOwner,
+		Status:    MemberStatusAccepted,
+		InvitedBy: *newOwner.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	event.UserID = *newOwner.UserID
+	event.UpdatedAt = now
+
+	err = svc.memberRepository.TransferOwnership(ctx, *event, *newOwner, previousOwner)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTransferConflict
+	}
+	if err != nil {
+		return fmt.Errorf("transferring ownership: %w", err)
+	}
+	return nil
+}
+
+func (svc *eventService) GetInvitations(ctx context.Context, principal rbac.Principal) ([]EventMember, error) {
+	if !principal.EmailVerified {
+		return nil, ErrEmailUnverified
+	}
+
+	invitations, err := svc.memberRepository.FindAllPendingByEmail(ctx, strings.ToLower(principal.Email))
+	if err != nil {
+		return nil, fmt.Errorf("finding invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (svc *eventService) RespondToInvitation(ctx context.Context, principal rbac.Principal, memberID string, accept bool) error {
+	if !principal.EmailVerified {
+		return ErrEmailUnverified
+	}
+
+	member, err := svc.memberRepository.FindOneByID(ctx, memberID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("finding invitation: %w", err)
+	}
+
+	if member == nil || member.Email != strings.ToLower(principal.Email) || member.Status != MemberStatusPending {
+		return ErrInvitationNotFound
+	}
+
+	member.Status = MemberStatusDeclined
+	if accept {
+		member.Status = MemberStatusAccepted
+		member.UserID = &principal.UserID
+	}
+	member.UpdatedAt = time.Now()
+
+	if err := svc.memberRepository.Update(ctx, *member); err != nil {
+		return fmt.Errorf("responding to invitation: %w", err)
+	}
+	return nil
+}
+
+func (svc *eventService) getEventMember(ctx context.Context, eventID, memberID string) (*EventMember, error) {
+	member, err := svc.memberRepository.FindOneByID(ctx, memberID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("finding event member: %w", err)
+	}
+
+	if member == nil || member.EventID != eventID {
+		return nil, ErrMemberNotFound
+	}
+
+	return member, nil
+}
+
+func (svc *eventService) isOwnerOrAdmin(principal rbac.Principal, event *Event) bool {
+	return event.UserID == principal.UserID || principal.Can(rbac.PermissionManageAnyEvent)
+}
+
+// authorize returns forbidden unless the principal may perform the action on the event,
+// either as its owner, as an admin, or through an accepted membership whose role allows it.
+func (svc *eventService) authorize(ctx context.Context, principal rbac.Principal, event *Event, action EventAction, forbidden error) error {
+	if svc.isOwnerOrAdmin(principal, event) {
+		return nil
+	}
+
+	member, err := svc.memberRepository.FindOneAcceptedByEventAndUser(ctx, event.ID, principal.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return forbidden
+	}
+	if err != nil {
+		return fmt.Errorf("finding event membership: %w", err)
 	}
 
-	if err := svc.repository.Delete(eventID); err != nil {
-		panic(HTTPException.NewBadRequestException("Failed to delete event", err.Error()))
+	if !member.Role.Can(action) {
+		return forbidden
 	}
+	return nil
 }
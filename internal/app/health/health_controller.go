@@ -0,0 +1,39 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController interface {
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
+
+type healthController struct {
+	service *HealthService
+}
+
+func NewHealthController(service *HealthService) HealthController {
+	return &healthController{service}
+}
+
+// Probe responses are plain JSON rather than the API envelope, since they are read by
+// orchestrators and load balancers, which mostly look at the status code.
+func (ctrl *healthController) Liveness(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, ctrl.service.Liveness())
+}
+
+func (ctrl *healthController) Readiness(ctx *gin.Context) {
+	report := ctrl.service.Readiness(ctx)
+
+	statusCode := http.StatusOK
+	if report.Status != StatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(statusCode, report)
+}
@@ -0,0 +1,15 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
+)
+
+// OpenAPIOperations documents the routes registered by RegisterRoutes.
+func OpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/healthz", Summary: "Liveness probe", Raw: Report{}},
+		{Method: http.MethodGet, Path: "/readyz", Summary: "Readiness probe", Description: "Responds 503 while a dependency is down or the server is shutting down.", Raw: Report{}},
+	}
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/buildinfo"
+)
+
+// checkTimeout bounds each dependency check so a hung dependency cannot hang the probe.
+const checkTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Check is a dependency the service needs in order to serve requests.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       Status                 `json:"status"`
+	ShuttingDown bool                   `json:"shutting_down,omitempty"`
+	Uptime       string                 `json:"uptime"`
+	Build        buildinfo.Info         `json:"build"`
+	Checks       map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type HealthService struct {
+	checks       []Check
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(checks ...Check) *HealthService {
+	return &HealthService{checks: checks, startedAt: time.Now()}
+}
+
+// BeginShutdown makes the service report not-ready, so that load balancers stop routing
+// to it while in-flight requests drain.
+func (s *HealthService) BeginShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// Liveness reports that the process is running; it deliberately ignores dependencies so an
+// outage of Postgres or Redis does not get every replica restarted.
+func (s *HealthService) Liveness() Report {
+	return Report{
+		Status: StatusUp,
+		Uptime: time.Since(s.startedAt).Round(time.Second).String(),
+		Build:  buildinfo.Get(),
+	}
+}
+
+// Readiness runs every dependency check concurrently and reports up only if all pass and
+// the service is not shutting down.
+func (s *HealthService) Readiness(ctx context.Context) Report {
+	report := s.Liveness()
+	report.Checks = make(map[string]CheckResult, len(s.checks))
+	report.ShuttingDown = s.shuttingDown.Load()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range s.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(checkCtx)
+			result := CheckResult{
+				Status:    StatusUp,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	if report.ShuttingDown {
+		report.Status = StatusDown
+	}
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,47 @@
+package dto
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin organizer attendee"`
+}
+
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,min=3"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=3"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,url"`
+	Phone     *string `json:"phone" validate:"omitempty,e164"`
+	Locale    *string `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	Timezone  *string `json:"timezone" validate:"omitempty,timezone"`
+}
+
+// ChangePasswordRequest is confirmed with the current password, or with a code from
+// RequestReauthentication for accounts that sign in without one.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required_without=OTP"`
+	OTP             string `json:"otp" validate:"required_without=CurrentPassword"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// ChangeEmailRequest is confirmed like ChangePasswordRequest.
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required_without=OTP"`
+	OTP      string `json:"otp" validate:"required_without=Password"`
+}
+
+type VerifyEmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	OTP      string `json:"otp" validate:"required"`
+}
+
+// DeleteAccountRequest is confirmed with the account's password, or with a code from
+// RequestAccountDeletion for accounts that sign in without one.
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required_without=OTP"`
+	OTP      string `json:"otp" validate:"required_without=Password"`
+}
+
+type DashboardCounts struct {
+	OrganizedEvents         int `json:"organized_events"`
+	UpcomingOrganizedEvents int `json:"upcoming_organized_events"`
+	ManagedEvents           int `json:"managed_events"`
+}
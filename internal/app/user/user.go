@@ -1,14 +1,24 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+)
 
 type User struct {
-	ID        string     `gorm:"primaryKey;not null" json:"id"`
-	FirstName string     `gorm:"not null" json:"first_name"`
-	LastName  string     `gorm:"not null" json:"last_name"`
-	Email     string     `gorm:"unique;not null" json:"email"`
-	Password  string     `gorm:"not null"`
-	CreatedAt time.Time  `gorm:"not null" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"default:NULL" json:"deleted_at,omitempty"`
+	ID              string     `gorm:"primaryKey;not null" json:"id"`
+	FirstName       string     `gorm:"not null" json:"first_name"`
+	LastName        string     `gorm:"not null" json:"last_name"`
+	Email           string     `gorm:"unique;not null" json:"email"`
+	EmailVerifiedAt *time.Time `gorm:"default:NULL" json:"email_verified_at,omitempty"`
+	Password        string     `gorm:"not null" json:"-"`
+	Role            rbac.Role  `gorm:"type:varchar(20);not null;default:attendee" json:"role"`
+	AvatarURL       *string    `gorm:"default:NULL" json:"avatar_url,omitempty"`
+	Phone           *string    `gorm:"default:NULL" json:"phone,omitempty"`
+	Locale          string     `gorm:"not null;default:en" json:"locale"`
+	Timezone        string     `gorm:"not null;default:UTC" json:"timezone"`
+	CreatedAt       time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"not null" json:"updated_at"`
+	DeletedAt       *time.Time `gorm:"default:NULL" json:"deleted_at,omitempty"`
 }
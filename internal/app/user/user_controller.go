@@ -1,19 +1,290 @@
 package user
 
 import (
+	"fmt"
+	"net/http"
+
+	UserDTO "github.com/edwinedjokpa/event-booking-api/internal/app/user/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+	APIResponse "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/apiresponse"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/validator"
+
 	"github.com/gin-gonic/gin"
 )
 
 type UserController interface {
 	Dashboard(c *gin.Context)
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RequestEmailChange(c *gin.Context)
+	VerifyEmailChange(c *gin.Context)
+	UpdateUserRole(c *gin.Context)
+	RequestDataExport(c *gin.Context)
+	GetDataExport(c *gin.Context)
+	DownloadDataExport(c *gin.Context)
+	RequestAccountDeletion(c *gin.Context)
+	RequestReauthentication(c *gin.Context)
+	DeleteAccount(c *gin.Context)
 }
 
 type userController struct {
-	service UserService
+	service   UserService
+	validator *validator.Validator
+}
+
+func NewUserController(service UserService, validator *validator.Validator) UserController {
+	return &userController{service, validator}
+}
+
+func (ctrl *userController) Dashboard(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	dashboard, err := ctrl.service.Dashboard(ctx, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.dashboard_retrieved"), gin.H{"dashboard": dashboard}))
+}
+
+func (ctrl *userController) GetProfile(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := ctrl.service.GetProfile(ctx, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.profile_retrieved"), gin.H{"user": user}))
+}
+
+func (ctrl *userController) UpdateProfile(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request UserDTO.UpdateProfileRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	user, err := ctrl.service.UpdateProfile(ctx, userID, request)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.profile_updated"), gin.H{"user": user}))
+}
+
+func (ctrl *userController) ChangePassword(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request UserDTO.ChangePasswordRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	if err := ctrl.service.ChangePassword(ctx, userID, ctx.GetString("sessionID"), request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.password_changed"), nil))
+}
+
+func (ctrl *userController) RequestEmailChange(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request UserDTO.ChangeEmailRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	if err := ctrl.service.RequestEmailChange(ctx, userID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.email_change_requested"), nil))
+}
+
+func (ctrl *userController) VerifyEmailChange(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request UserDTO.VerifyEmailChangeRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	if err := ctrl.service.VerifyEmailChange(ctx, userID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.email_changed"), nil))
+}
+
+func (ctrl *userController) UpdateUserRole(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var request UserDTO.UpdateUserRoleRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	if err := ctrl.service.UpdateUserRole(ctx, userID, rbac.Role(request.Role)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.role_updated"), nil))
+}
+
+func (ctrl *userController) RequestDataExport(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	job, err := ctrl.service.RequestDataExport(ctx, userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, APIResponse.Success(i18n.T(ctx, "user.data_export_requested"), gin.H{"export": job}))
+}
+
+func (ctrl *userController) GetDataExport(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	job, _, err := ctrl.service.GetDataExport(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.data_export_retrieved"), gin.H{"export": job}))
+}
+
+func (ctrl *userController) DownloadDataExport(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	job, archivePath, err := ctrl.service.GetDataExport(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if archivePath == "" {
+		ctx.Error(ErrDataExportNotReady.WithDetails(gin.H{"status": job.Status}))
+		return
+	}
+
+	ctx.FileAttachment(archivePath, fmt.Sprintf("data-export-%s.zip", job.ID))
+}
+
+func (ctrl *userController) RequestAccountDeletion(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.RequestAccountDeletion(ctx, userID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.account_deletion_requested"), nil))
 }
 
-func NewUserController(service UserService) UserController {
-	return &userController{service}
+func (ctrl *userController) RequestReauthentication(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.RequestReauthentication(ctx, userID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.reauthentication_requested"), nil))
 }
 
-func (ctrl *userController) Dashboard(ctx *gin.Context) {}
+func (ctrl *userController) DeleteAccount(ctx *gin.Context) {
+	userID, ok := ctrl.currentUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var request UserDTO.DeleteAccountRequest
+	if !ctrl.bindAndValidate(ctx, &request) {
+		return
+	}
+
+	if err := ctrl.service.DeleteAccount(ctx, userID, request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIResponse.Success(i18n.T(ctx, "user.account_deleted"), nil))
+}
+
+func (ctrl *userController) currentUserID(ctx *gin.Context) (string, bool) {
+	userIDRaw, exists := ctx.Get("userID")
+	if !exists {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return "", false
+	}
+
+	userID, ok := userIDRaw.(string)
+	if !ok {
+		ctx.Error(middleware.ErrUnauthenticated)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func (ctrl *userController) bindAndValidate(ctx *gin.Context, request interface{}) bool {
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		ctx.Error(ctrl.validator.DecodeError(ctx, err))
+		return false
+	}
+
+	if err := ctrl.validator.Struct(ctx, request); err != nil {
+		ctx.Error(err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,13 @@
+package user
+
+import "time"
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	ID        string    `gorm:"primaryKey;not null" json:"id"`
+	UserID    string    `gorm:"not null;index" json:"user_id"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email     string    `gorm:"not null" json:"email"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
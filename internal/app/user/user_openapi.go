@@ -0,0 +1,43 @@
+package user
+
+import (
+	"net/http"
+
+	UserDTO "github.com/edwinedjokpa/event-booking-api/internal/app/user/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/openapi"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/export"
+)
+
+const reauthenticationDescription = "Confirmed with the account's current password, or with a code from POST /users/me/reauthentication."
+
+// OpenAPIOperations documents the routes registered by RegisterRoutes.
+func OpenAPIOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/users/me", Summary: "Get the caller's profile", Security: openapi.SecurityAny, Data: openapi.Data{"user": User{}}},
+		{Method: http.MethodPatch, Path: "/users/me", Summary: "Update the caller's profile", Security: openapi.SecuritySession, Body: UserDTO.UpdateProfileRequest{}, Data: openapi.Data{"user": User{}}},
+		{Method: http.MethodGet, Path: "/users/me/dashboard", Summary: "Get the caller's dashboard", Security: openapi.SecurityAny, Data: openapi.Data{"dashboard": UserDTO.DashboardCounts{}}},
+		{Method: http.MethodPost, Path: "/users/me/reauthentication", Summary: "Email a code to confirm a password or email change", Security: openapi.SecuritySession},
+		{
+			Method: http.MethodPost, Path: "/users/me/password", Summary: "Change the caller's password", Security: openapi.SecuritySession,
+			Description: reauthenticationDescription,
+			Body:        UserDTO.ChangePasswordRequest{},
+		},
+		{
+			Method: http.MethodPost, Path: "/users/me/email", Summary: "Email a code to confirm a new address", Security: openapi.SecuritySession,
+			Description: reauthenticationDescription,
+			Body:        UserDTO.ChangeEmailRequest{},
+		},
+		{Method: http.MethodPost, Path: "/users/me/email/verify", Summary: "Confirm a new email address", Security: openapi.SecuritySession, Body: UserDTO.VerifyEmailChangeRequest{}},
+		{Method: http.MethodPost, Path: "/users/me/deletion", Summary: "Email a code to confirm deleting the caller's account", Security: openapi.SecuritySession},
+		{
+			Method: http.MethodDelete, Path: "/users/me", Summary: "Delete the caller's account", Security: openapi.SecuritySession,
+			Description: "Confirmed with the account's password, or with a code from POST /users/me/deletion.",
+			Body:        UserDTO.DeleteAccountRequest{},
+		},
+		{Method: http.MethodPost, Path: "/users/me/export", Summary: "Start a data export", Security: openapi.SecuritySession, Status: http.StatusAccepted, Data: openapi.Data{"export": export.Job{}}},
+		{Method: http.MethodGet, Path: "/users/me/export/:id", Summary: "Get a data export's status", Security: openapi.SecuritySession, Data: openapi.Data{"export": export.Job{}}},
+		{Method: http.MethodGet, Path: "/users/me/export/:id/download", Summary: "Download a finished data export", Security: openapi.SecuritySession, ContentType: "application/zip"},
+
+		{Method: http.MethodPatch, Path: "/admin/users/:id/role", Summary: "Change a user's role", Security: openapi.SecurityAny, Body: UserDTO.UpdateUserRoleRequest{}},
+	}
+}
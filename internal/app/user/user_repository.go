@@ -1,13 +1,27 @@
 package user
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+
+	"gorm.io/gorm"
+)
 
 type UserRepository interface {
-	Create(user User) error
-	FindOneByID(userId string) (*User, error)
-	Update(user User) error
-	FindOneByEmail(email string) (*User, error)
-	UpdatePassword(userID, hashedPassword string) error
+	Create(ctx context.Context, user User) error
+	FindOneByID(ctx context.Context, userId string) (*User, error)
+	Update(ctx context.Context, user User) error
+	FindOneByEmail(ctx context.Context, email string) (*User, error)
+	UpdatePassword(ctx context.Context, userID, hashedPassword string) error
+	UpdateRole(ctx context.Context, userID string, role rbac.Role) error
+	MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error
+	FindOneByIdentity(ctx context.Context, provider, subject string) (*User, error)
+	CreateIdentity(ctx context.Context, identity UserIdentity) error
+	CreateWithIdentity(ctx context.Context, user User, identity UserIdentity) error
+	FindAllIdentities(ctx context.Context, userID string) ([]UserIdentity, error)
+	DeleteAllIdentities(ctx context.Context, userID string) error
 }
 
 type userRepository struct {
@@ -18,39 +32,39 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db}
 }
 
-func (repo *userRepository) Create(user User) error {
-	if err := repo.db.Create(&user).Error; err != nil {
+func (repo *userRepository) Create(ctx context.Context, user User) error {
+	if err := repo.db.WithContext(ctx).Create(&user).Error; err != nil {
 		return err
 	}
 	return nil
 
 }
 
-func (repo *userRepository) FindOneByID(userID string) (*User, error) {
+func (repo *userRepository) FindOneByID(ctx context.Context, userID string) (*User, error) {
 	var user User
-	if err := repo.db.First(&user, "id = ?", userID).Error; err != nil {
+	if err := repo.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (repo *userRepository) Update(user User) error {
-	if err := repo.db.Save(&user).Error; err != nil {
+func (repo *userRepository) Update(ctx context.Context, user User) error {
+	if err := repo.db.WithContext(ctx).Save(&user).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (repo *userRepository) FindOneByEmail(email string) (*User, error) {
+func (repo *userRepository) FindOneByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	if err := repo.db.Model(&User{}).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := repo.db.WithContext(ctx).Model(&User{}).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (repo *userRepository) UpdatePassword(userID string, hashedPassword string) error {
-	result := repo.db.Model(&User{}).Where("id = ?", userID).Update("password", hashedPassword)
+func (repo *userRepository) UpdatePassword(ctx context.Context, userID string, hashedPassword string) error {
+	result := repo.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("password", hashedPassword)
 
 	if result.Error != nil {
 		return result.Error
@@ -62,3 +76,72 @@ func (repo *userRepository) UpdatePassword(userID string, hashedPassword string)
 
 	return nil
 }
+
+func (repo *userRepository) UpdateRole(ctx context.Context, userID string, role rbac.Role) error {
+	result := repo.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("role", role)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repo *userRepository) MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error {
+	result := repo.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("email_verified_at", verifiedAt)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repo *userRepository) FindOneByIdentity(ctx context.Context, provider, subject string) (*User, error) {
+	var user User
+	err := repo.db.WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject = ?", provider, subject).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (repo *userRepository) CreateIdentity(ctx context.Context, identity UserIdentity) error {
+	if err := repo.db.WithContext(ctx).Create(&identity).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateWithIdentity creates a user together with their first linked identity.
+func (repo *userRepository) CreateWithIdentity(ctx context.Context, user User, identity UserIdentity) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&identity).Error
+	})
+}
+
+func (repo *userRepository) FindAllIdentities(ctx context.Context, userID string) ([]UserIdentity, error) {
+	var identities []UserIdentity
+	if err := repo.db.WithContext(ctx).Where("user_id = ?", userID).Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (repo *userRepository) DeleteAllIdentities(ctx context.Context, userID string) error {
+	return repo.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&UserIdentity{}).Error
+}
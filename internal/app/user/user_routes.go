@@ -1,7 +1,33 @@
 package user
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/middleware"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
 
-func RegisterRoutes(router *gin.RouterGroup, controller UserController) {
-	router.POST("/dashboard", controller.Dashboard)
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, controller UserController, authMiddleware gin.HandlerFunc) {
+	userRouter := router.Group("/users/me")
+	userRouter.Use(authMiddleware)
+	{
+		userRouter.GET("", controller.GetProfile)
+		userRouter.PATCH("", middleware.RequireSession(), controller.UpdateProfile)
+		userRouter.GET("/dashboard", controller.Dashboard)
+		userRouter.POST("/reauthentication", middleware.RequireSession(), controller.RequestReauthentication)
+		userRouter.POST("/password", middleware.RequireSession(), controller.ChangePassword)
+		userRouter.POST("/email", middleware.RequireSession(), controller.RequestEmailChange)
+		userRouter.POST("/email/verify", middleware.RequireSession(), controller.VerifyEmailChange)
+		userRouter.POST("/deletion", middleware.RequireSession(), controller.RequestAccountDeletion)
+		userRouter.DELETE("", middleware.RequireSession(), controller.DeleteAccount)
+		userRouter.POST("/export", middleware.RequireSession(), controller.RequestDataExport)
+		userRouter.GET("/export/:id", middleware.RequireSession(), controller.GetDataExport)
+		userRouter.GET("/export/:id/download", middleware.RequireSession(), controller.DownloadDataExport)
+	}
+
+	adminRouter := router.Group("/admin")
+	adminRouter.Use(authMiddleware, middleware.RequirePermission(rbac.PermissionManageUsers))
+	{
+		adminRouter.PATCH("/users/:id/role", controller.UpdateUserRole)
+	}
 }
@@ -1,22 +1,473 @@
 package user
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
+	UserDTO "github.com/edwinedjokpa/event-booking-api/internal/app/user/dto"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/denylist"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/export"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/otp"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/session"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound    = apperror.NotFound("user_not_found", "User not found")
+	ErrInvalidPassword = apperror.Validation("invalid_current_password", "Current password is incorrect")
+	ErrEmailTaken      = apperror.Conflict("email_taken", "User with email already exists")
+	// ErrDataExportNotReady is returned when downloading an export that has not completed.
+	ErrDataExportNotReady = apperror.Conflict("data_export_not_ready", "Data export is not ready for download")
+)
+
+type Dashboard struct {
+	User                    *User                   `json:"user"`
+	UpcomingOrganizedEvents []event.Event           `json:"upcoming_organized_events"`
+	ManagedEvents           []event.Event           `json:"managed_events"`
+	Counts                  UserDTO.DashboardCounts `json:"counts"`
+}
+
 type UserService interface {
-	Dashboard(userID string) (*User, error)
+	Dashboard(ctx context.Context, userID string) (*Dashboard, error)
+	GetProfile(ctx context.Context, userID string) (*User, error)
+	UpdateProfile(ctx context.Context, userID string, request UserDTO.UpdateProfileRequest) (*User, error)
+	ChangePassword(ctx context.Context, userID, currentSessionID string, request UserDTO.ChangePasswordRequest) error
+	RequestEmailChange(ctx context.Context, userID string, request UserDTO.ChangeEmailRequest) error
+	VerifyEmailChange(ctx context.Context, userID string, request UserDTO.VerifyEmailChangeRequest) error
+	UpdateUserRole(ctx context.Context, userID string, role rbac.Role) error
+	RequestDataExport(ctx context.Context, userID string) (*export.Job, error)
+	GetDataExport(ctx context.Context, userID, exportID string) (*export.Job, string, error)
+	CollectUserData(ctx context.Context, userID string) (map[string]interface{}, error)
+	RequestAccountDeletion(ctx context.Context, userID string) error
+	RequestReauthentication(ctx context.Context, userID string) error
+	DeleteAccount(ctx context.Context, userID string, request UserDTO.DeleteAccountRequest) error
 }
 
 type userService struct {
-	repository UserRepository
+	repository            UserRepository
+	eventRepository       event.EventRepository
+	eventMemberRepository event.EventMemberRepository
+	sessionService        *session.SessionService
+	denylistService       *denylist.DenylistService
+	otpService            otp.OTPService
+	exportService         *export.ExportService
+	logger                *slog.Logger
 }
 
-func NewUserService(repository UserRepository) UserService {
-	return &userService{repository}
+func NewUserService(
+	repository UserRepository,
+	eventRepository event.EventRepository,
+	eventMemberRepository event.EventMemberRepository,
+	sessionService *session.SessionService,
+	denylistService *denylist.DenylistService,
+	otpService otp.OTPService,
+	exportService *export.ExportService,
+	logger *slog.Logger,
+) UserService {
+	return &userService{repository, eventRepository, eventMemberRepository, sessionService, denylistService, otpService, exportService, logger}
 }
 
-func (svc *userService) Dashboard(userID string) (*User, error) {
-	user, err := svc.repository.FindOneByID(userID)
+func (svc *userService) Dashboard(ctx context.Context, userID string) (*Dashboard, error) {
+	user, err := svc.findUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	organizedEvents, err := svc.eventRepository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	managedEvents, err := svc.eventRepository.FindAllManagedByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	upcomingEvents := make([]event.Event, 0, len(organizedEvents))
+	for _, organizedEvent := range organizedEvents {
+		if organizedEvent.Date.After(now) {
+			upcomingEvents = append(upcomingEvents, organizedEvent)
+		}
+	}
+
+	return &Dashboard{
+		User:                    user,
+		UpcomingOrganizedEvents: upcomingEvents,
+		ManagedEvents:           managedEvents,
+		Counts: UserDTO.DashboardCounts{
+			OrganizedEvents:         len(organizedEvents),
+			UpcomingOrganizedEvents: len(upcomingEvents),
+			ManagedEvents:           len(managedEvents),
+		},
+	}, nil
+}
+
+func (svc *userService) GetProfile(ctx context.Context, userID string) (*User, error) {
+	return svc.findUser(ctx, userID)
+}
+
+func (svc *userService) UpdateProfile(ctx context.Context, userID string, request UserDTO.UpdateProfileRequest) (*User, error) {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.FirstName != nil {
+		user.FirstName = *request.FirstName
+	}
+
+	if request.LastName != nil {
+		user.LastName = *request.LastName
+	}
+
+	if request.AvatarURL != nil {
+		user.AvatarURL = optionalString(*request.AvatarURL)
+	}
+
+	if request.Phone != nil {
+		user.Phone = optionalString(*request.Phone)
+	}
+
+	if request.Locale != nil {
+		user.Locale = *request.Locale
+	}
+
+	if request.Timezone != nil {
+		user.Timezone = *request.Timezone
+	}
+
+	user.UpdatedAt = time.Now()
+
+	if err := svc.repository.Update(ctx, *user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
+
+// ChangePassword updates the password after checking the current one, and signs the user
+// out of every session other than the one making the change.
+func (svc *userService) ChangePassword(ctx context.Context, userID, currentSessionID string, request UserDTO.ChangePasswordRequest) error {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.confirmIdentity(ctx, user, otp.PurposeReauthentication, request.CurrentPassword, request.OTP); err != nil {
+		return err
+	}
+
+	hashedPassword, err := util.HashPassword(request.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.repository.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	sessions, err := svc.sessionService.ListUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionData := range sessions {
+		if sessionData.ID == currentSessionID {
+			continue
+		}
+
+		if err := svc.sessionService.DeleteSession(ctx, sessionData.ID); err != nil {
+			return err
+		}
+
+		if err := svc.denylistService.RevokeSession(ctx, sessionData.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RequestEmailChange sends a verification code to the new address. The email is only
+// changed once that code is confirmed via VerifyEmailChange.
+func (svc *userService) RequestEmailChange(ctx context.Context, userID string, request UserDTO.ChangeEmailRequest) error {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.confirmIdentity(ctx, user, otp.PurposeReauthentication, request.Password, request.OTP); err != nil {
+		return err
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(request.NewEmail))
+	if err := svc.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	code, err := svc.otpService.GenerateAndStoreOTP(ctx, otp.PurposeEmailChange, newEmail)
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.Match(user.Locale)
+	logging.FromContext(ctx, svc.logger).Info("Email change OTP issued",
+		slog.String("email", newEmail),
+		slog.String("locale", locale),
+		slog.String("subject", i18n.Message(locale, "email.email_change.subject")),
+		slog.String("otp", code),
+	)
+	return nil
+}
+
+func (svc *userService) VerifyEmailChange(ctx context.Context, userID string, request UserDTO.VerifyEmailChangeRequest) error {
+	newEmail := strings.ToLower(strings.TrimSpace(request.NewEmail))
+
+	if err := svc.otpService.ValidateOTP(ctx, otp.PurposeEmailChange, newEmail, request.OTP); err != nil {
+		return err
+	}
+
+	if err := svc.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Email = newEmail
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+
+	if err := svc.repository.Update(ctx, *user); err != nil {
+		return err
+	}
+
+	// Outstanding access tokens still carry the old address.
+	return svc.denylistService.RevokeUserTokens(ctx, userID)
+}
+
+// UpdateUserRole changes the user's role and invalidates their outstanding access tokens,
+// so the new permissions apply on their next refresh rather than when the old tokens expire.
+func (svc *userService) UpdateUserRole(ctx context.Context, userID string, role rbac.Role) error {
+	if err := svc.repository.UpdateRole(ctx, userID, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return svc.denylistService.RevokeUserTokens(ctx, userID)
+}
+
+func (svc *userService) RequestDataExport(ctx context.Context, userID string) (*export.Job, error) {
+	if _, err := svc.findUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return svc.exportService.Enqueue(ctx, util.GenerateUUID(), userID)
+}
+
+// GetDataExport returns the export and, once it has completed, the path of its archive.
+func (svc *userService) GetDataExport(ctx context.Context, userID, exportID string) (*export.Job, string, error) {
+	job, err := svc.exportService.GetJob(ctx, userID, exportID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if job.Status != export.JobStatusCompleted {
+		return job, "", nil
+	}
+
+	return job, svc.exportService.ArchivePath(job.ID), nil
+}
+
+// CollectUserData gathers everything we hold about the user for a data export. Nothing is
+// collected for a deleted account, whose export may still have been queued.
+func (svc *userService) CollectUserData(ctx context.Context, userID string) (map[string]interface{}, error) {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+
+	organizedEvents, err := svc.eventRepository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := svc.eventMemberRepository.FindAllByUser(ctx, userID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := svc.repository.FindAllIdentities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := svc.sessionService.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"user":              user,
+		"organized_events":  organizedEvents,
+		"event_memberships": memberships,
+		"linked_identities": identities,
+		"sessions":          sessions,
+	}, nil
+}
+
+// RequestAccountDeletion emails a code that confirms DeleteAccount, for users who sign in
+// with passwordless codes or an identity provider and so have no password to confirm it with.
+func (svc *userService) RequestAccountDeletion(ctx context.Context, userID string) error {
+	return svc.sendConfirmationCode(ctx, userID, otp.PurposeAccountDeletion, "email.account_deletion.subject")
+}
+
+// RequestReauthentication emails a code that confirms ChangePassword or RequestEmailChange
+// in place of the current password.
+func (svc *userService) RequestReauthentication(ctx context.Context, userID string) error {
+	return svc.sendConfirmationCode(ctx, userID, otp.PurposeReauthentication, "email.reauthentication.subject")
+}
+
+func (svc *userService) sendConfirmationCode(ctx context.Context, userID string, purpose otp.Purpose, subjectKey string) error {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	code, err := svc.otpService.GenerateAndStoreOTP(ctx, purpose, user.Email)
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.Match(user.Locale)
+	logging.FromContext(ctx, svc.logger).Info("Confirmation OTP issued",
+		slog.String("email", user.Email),
+		slog.String("purpose", string(purpose)),
+		slog.String("locale", locale),
+		slog.String("subject", i18n.Message(locale, subjectKey)),
+		slog.String("otp", code),
+	)
+	return nil
+}
+
+// confirmIdentity checks the code emailed for purpose when one is given, and the user's
+// password otherwise.
+func (svc *userService) confirmIdentity(ctx context.Context, user *User, purpose otp.Purpose, password, code string) error {
+	if code != "" {
+		return svc.otpService.ValidateOTP(ctx, purpose, user.Email, code)
+	}
+
+	if !util.CheckPasswordHash(user.Password, password) {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// DeleteAccount erases the user's personal data. The user row is kept, anonymized, so that
+// events and other records needed for accounting still reference a valid account.
+func (svc *userService) DeleteAccount(ctx context.Context, userID string, request UserDTO.DeleteAccountRequest) error {
+	user, err := svc.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.confirmIdentity(ctx, user, otp.PurposeAccountDeletion, request.Password, request.OTP); err != nil {
+		return err
+	}
+
+	if err := svc.eventMemberRepository.DeleteAllByUser(ctx, userID, user.Email); err != nil {
+		return err
+	}
+
+	if err := svc.repository.DeleteAllIdentities(ctx, userID); err != nil {
+		return err
+	}
+
+	unusablePassword, err := util.HashPassword(util.GenerateUUID())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.FirstName = "Deleted"
+	user.LastName = "User"
+	user.Email = fmt.Sprintf("deleted+%s@users.invalid", user.ID)
+	user.EmailVerifiedAt = nil
+	user.Password = unusablePassword
+	user.Role = rbac.RoleAttendee
+	user.AvatarURL = nil
+	user.Phone = nil
+	user.UpdatedAt = now
+	user.DeletedAt = &now
+
+	if err := svc.repository.Update(ctx, *user); err != nil {
+		return err
+	}
+
+	if err := svc.exportService.DeleteUserJobs(ctx, userID); err != nil {
+		return err
+	}
+
+	sessionIDs, err := svc.sessionService.DeleteAllUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := svc.denylistService.RevokeSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	return svc.denylistService.RevokeUserTokens(ctx, userID)
+}
+
+// findUser is FindOneByID with a missing user reported as ErrUserNotFound.
+func (svc *userService) findUser(ctx context.Context, userID string) (*User, error) {
+	user, err := svc.repository.FindOneByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (svc *userService) ensureEmailAvailable(ctx context.Context, email string) error {
+	existingUser, err := svc.repository.FindOneByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if existingUser != nil {
+		return ErrEmailTaken
+	}
+
+	return nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,10 +24,69 @@ type DBConfig struct {
 }
 
 type Config struct {
+	// Environment is "production" or anything else for development.
+	Environment string
 	Port        string
 	DatabaseURL string
 	RedisAddr   string
-	JWTSecret   string
+	// JWTSecret signs HS256 tokens when no signing keys are configured. While migrating to
+	// asymmetric keys it may be kept set so previously issued HS256 tokens still verify,
+	// until the first signing key has been active for JWTKeyGracePeriod.
+	JWTSecret string
+	// JWTSigningKeys is the key rotation schedule, see util.LoadSigningKeys for the format.
+	JWTSigningKeys string
+	// JWTKeyGracePeriod is how long a superseded signing key keeps verifying tokens.
+	JWTKeyGracePeriod time.Duration
+	// DataExportDir is where user data-export archives are written.
+	DataExportDir string
+	// PasswordlessSignupEnabled lets a passwordless login create an account for an
+	// email address that does not have one yet.
+	PasswordlessSignupEnabled bool
+	// PasswordlessLinkURL is the page a sign-in link opens. It receives the token in the
+	// "token" query parameter and exchanges it at /api/auth/passwordless/verify.
+	PasswordlessLinkURL string
+	// ShutdownDrainPeriod is how long the server keeps serving after a termination signal,
+	// giving load balancers time to stop routing new requests to it.
+	ShutdownDrainPeriod time.Duration
+	// ShutdownTimeout bounds how long in-flight requests and background work may take to finish.
+	ShutdownTimeout time.Duration
+	// MetricsToken, when set, must be sent as a bearer token to scrape /metrics.
+	MetricsToken string
+	// OIDCProviders are the OpenID Connect providers users may sign in with.
+	OIDCProviders []OIDCProviderConfig
+	// TracingExporter is where spans are sent: "otlp", "stdout", "file" or "none". The OTLP
+	// exporter is configured through the standard OTEL_EXPORTER_OTLP_* variables.
+	TracingExporter string
+	// TracingFile is the file spans are appended to when TracingExporter is "file".
+	TracingFile string
+	// TracingSampleRatio is the fraction of new traces that are recorded. Requests that
+	// arrive with a sampled parent are always recorded.
+	TracingSampleRatio float64
+	// LogFormat is "json" or "text". It defaults to JSON in production.
+	LogFormat string
+	// LogLevel is the minimum level logged: debug, info, warn or error.
+	LogLevel slog.Level
+	// LogRedact hides passwords, tokens and codes in logs. It can only be turned off
+	// outside production.
+	LogRedact bool
+	// CookieSecure marks the refresh token and login state cookies Secure, so browsers only
+	// send them over HTTPS. It defaults to on in production, where it cannot be turned off.
+	CookieSecure bool
+}
+
+// OIDCProviderConfig is read from OIDC_<NAME>_* variables for every name listed in
+// OIDC_PROVIDERS. The endpoint URLs are optional and override discovery, which is
+// useful for providers whose discovery document is incomplete.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
 }
 
 func LoadConfig() (*Config, error) {
@@ -30,16 +96,32 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config := &Config{
-		Port:        os.Getenv("PORT"),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		RedisAddr:   os.Getenv("REDIS_URL"),
-		JWTSecret:   os.Getenv("JWT_SECRET"),
+		Environment:    os.Getenv("APP_ENV"),
+		Port:           os.Getenv("PORT"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		RedisAddr:      os.Getenv("REDIS_URL"),
+		JWTSecret:      os.Getenv("JWT_SECRET"),
+		JWTSigningKeys: os.Getenv("JWT_SIGNING_KEYS"),
+		DataExportDir:  os.Getenv("DATA_EXPORT_DIR"),
+
+		PasswordlessLinkURL: os.Getenv("PASSWORDLESS_LINK_URL"),
+		MetricsToken:        os.Getenv("METRICS_TOKEN"),
+		TracingExporter:     strings.ToLower(os.Getenv("TRACING_EXPORTER")),
+		TracingFile:         os.Getenv("TRACING_FILE"),
+	}
+
+	if config.Environment == "" {
+		config.Environment = "development"
 	}
 
 	if config.Port == "" {
 		config.Port = "9000"
 	}
 
+	if config.DataExportDir == "" {
+		config.DataExportDir = filepath.Join(os.TempDir(), "event-booking-exports")
+	}
+
 	if config.DatabaseURL == "" {
 		return nil, os.ErrNotExist
 	}
@@ -48,9 +130,150 @@ func LoadConfig() (*Config, error) {
 		return nil, os.ErrNotExist
 	}
 
-	if config.JWTSecret == "" {
+	if config.JWTSecret == "" && config.JWTSigningKeys == "" {
 		return nil, os.ErrNotExist
 	}
 
+	config.JWTKeyGracePeriod = 7 * 24 * time.Hour
+	if gracePeriod := os.Getenv("JWT_KEY_GRACE_PERIOD"); gracePeriod != "" {
+		config.JWTKeyGracePeriod, err = time.ParseDuration(gracePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_KEY_GRACE_PERIOD: %w", err)
+		}
+	}
+
+	config.ShutdownDrainPeriod, err = durationFromEnv("SHUTDOWN_DRAIN_PERIOD", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ShutdownTimeout, err = durationFromEnv("SHUTDOWN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	if signupEnabled := os.Getenv("PASSWORDLESS_SIGNUP_ENABLED"); signupEnabled != "" {
+		config.PasswordlessSignupEnabled, err = strconv.ParseBool(signupEnabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PASSWORDLESS_SIGNUP_ENABLED: %w", err)
+		}
+	}
+
+	switch config.TracingExporter {
+	case "":
+		config.TracingExporter = "none"
+	case "none", "otlp", "stdout", "file":
+	default:
+		return nil, fmt.Errorf("invalid TRACING_EXPORTER %q", config.TracingExporter)
+	}
+
+	if config.TracingExporter == "file" && config.TracingFile == "" {
+		config.TracingFile = "traces.json"
+	}
+
+	config.TracingSampleRatio = 1
+	if sampleRatio := os.Getenv("TRACING_SAMPLE_RATIO"); sampleRatio != "" {
+		config.TracingSampleRatio, err = strconv.ParseFloat(sampleRatio, 64)
+		if err != nil || config.TracingSampleRatio < 0 || config.TracingSampleRatio > 1 {
+			return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO %q: must be between 0 and 1", sampleRatio)
+		}
+	}
+
+	config.LogFormat = strings.ToLower(os.Getenv("LOG_FORMAT"))
+	switch config.LogFormat {
+	case "":
+		config.LogFormat = "text"
+		if config.IsProduction() {
+			config.LogFormat = "json"
+		}
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q", config.LogFormat)
+	}
+
+	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
+		if err := config.LogLevel.UnmarshalText([]byte(logLevel)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+	}
+
+	config.LogRedact = true
+	if logRedact := os.Getenv("LOG_REDACT"); logRedact != "" {
+		config.LogRedact, err = strconv.ParseBool(logRedact)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOG_REDACT: %w", err)
+		}
+	}
+
+	if !config.LogRedact && config.IsProduction() {
+		return nil, errors.New("LOG_REDACT cannot be turned off in production")
+	}
+
+	config.CookieSecure = config.IsProduction()
+	if cookieSecure := os.Getenv("COOKIE_SECURE"); cookieSecure != "" {
+		config.CookieSecure, err = strconv.ParseBool(cookieSecure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid COOKIE_SECURE: %w", err)
+		}
+	}
+
+	if !config.CookieSecure && config.IsProduction() {
+		return nil, errors.New("COOKIE_SECURE cannot be turned off in production")
+	}
+
+	config.OIDCProviders, err = loadOIDCProviders(os.Getenv("OIDC_PROVIDERS"))
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
+
+func (config *Config) IsProduction() bool {
+	return config.Environment == "production"
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return duration, nil
+}
+
+func loadOIDCProviders(names string) ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			JWKSURL:      os.Getenv(prefix + "JWKS_URL"),
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return nil, fmt.Errorf("OIDC provider %q requires %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
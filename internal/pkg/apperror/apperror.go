@@ -0,0 +1,104 @@
+// Package apperror defines the failures services report to their callers. Each error has a
+// kind, which decides how it is presented (for example the HTTP status), and a stable
+// machine-readable code that clients can branch on. Any other error is an internal error.
+package apperror
+
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindForbidden    Kind = "forbidden"
+	KindUnauthorized Kind = "unauthorized"
+	KindValidation   Kind = "validation"
+	// KindUnavailable is a dependency failure that is expected to pass, so the client may retry.
+	KindUnavailable Kind = "unavailable"
+	// KindPreconditionRequired and KindPreconditionFailed reject conditional requests, such as
+	// an update sent without the version it was based on, or based on an outdated version.
+	KindPreconditionRequired Kind = "precondition_required"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	// KindRateLimited rejects a caller who has made too many requests, until a window passes.
+	KindRateLimited Kind = "rate_limited"
+)
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Details is extra information for the client, such as the fields that failed validation.
+	Details interface{}
+	cause   error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches any error with the same code, so errors.Is works against the package-level
+// error values even when a copy carries details or a cause.
+func (e *Error) Is(target error) bool {
+	targetErr, ok := target.(*Error)
+	return ok && targetErr.Code == e.Code
+}
+
+// WithDetails returns a copy of the error carrying details for the client.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithCause returns a copy of the error wrapping the underlying failure. The cause is for
+// logs only and is never shown to the client.
+func (e *Error) WithCause(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+func PreconditionRequired(code, message string) *Error {
+	return New(KindPreconditionRequired, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPreconditionFailed, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
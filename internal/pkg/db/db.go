@@ -1,13 +1,6 @@
 package db
 
 import (
-	"fmt"
-	"log"
-	"reflect"
-
-	"github.com/edwinedjokpa/event-booking-api/internal/app/event"
-	"github.com/edwinedjokpa/event-booking-api/internal/app/user"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -23,20 +16,3 @@ func NewGormDB(databaseURL string) (*gorm.DB, error) {
 	}
 	return db, nil
 }
-
-func RunMigrations(db *gorm.DB) {
-	modelsToMigrate := []any{
-		&user.User{},
-		&event.Event{},
-	}
-
-	fmt.Println("Checking all registered models:")
-	for _, model := range modelsToMigrate {
-		fmt.Println(" - ", reflect.TypeOf(model))
-	}
-
-	if err := db.AutoMigrate(&user.User{}, &event.Event{}); err != nil {
-		log.Fatalf("Error running migrations: %v", err)
-	}
-	log.Println("Database migrations executed successfully!")
-}
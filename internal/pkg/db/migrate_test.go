@@ -0,0 +1,73 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s: want version %d, migrations must be numbered without gaps", migration.Version, migration.Name, i+1)
+		}
+	}
+}
+
+func TestMigrationChecksumCoversOnlyUpScript(t *testing.T) {
+	load := func(up, down string) Migration {
+		t.Helper()
+		migrations, err := loadMigrations(fstest.MapFS{
+			"migrations/0001_init.up.sql":   {Data: []byte(up)},
+			"migrations/0001_init.down.sql": {Data: []byte(down)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return migrations[0]
+	}
+
+	original := load("CREATE TABLE a ();", "DROP TABLE a;")
+	if load("CREATE TABLE a ();", "DROP TABLE IF EXISTS a;").Checksum != original.Checksum {
+		t.Fatal("editing the down script changed the checksum")
+	}
+	if load("CREATE TABLE b ();", "DROP TABLE a;").Checksum == original.Checksum {
+		t.Fatal("editing the up script kept the checksum")
+	}
+}
+
+func TestCheckAppliedRejectsModifiedMigration(t *testing.T) {
+	migrations, err := loadMigrations(fstest.MapFS{
+		"migrations/0001_init.up.sql":  {Data: []byte("CREATE TABLE a ();")},
+		"migrations/0002_more.up.sql":  {Data: []byte("CREATE TABLE b ();")},
+		"migrations/0003_extra.up.sql": {Data: []byte("CREATE TABLE c ();")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied := map[int64]appliedMigration{
+		1: {name: "init", checksum: migrations[0].Checksum},
+		2: {name: "more", checksum: migrations[1].Checksum},
+	}
+	if err := checkApplied(migrations, applied); err != nil {
+		t.Fatalf("unchanged migrations: %v", err)
+	}
+
+	applied[2] = appliedMigration{name: "more", checksum: "checksum of an earlier edit"}
+	err = checkApplied(migrations, applied)
+	if err == nil || !strings.Contains(err.Error(), "2_more has been modified") {
+		t.Fatalf("modified migration: got %v", err)
+	}
+
+	delete(applied, 2)
+	applied[4] = appliedMigration{name: "newer", checksum: "x"}
+	err = checkApplied(migrations, applied)
+	if err == nil || !strings.Contains(err.Error(), "does not know about") {
+		t.Fatalf("unknown applied migration: got %v", err)
+	}
+}
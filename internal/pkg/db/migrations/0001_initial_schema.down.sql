@@ -0,0 +1,8 @@
+-- The baseline may have been adopted from a database managed by GORM AutoMigrate, so its
+-- tables can hold everything written before versioned migrations. Dropping them is never
+-- what reverting a migration should do.
+DO $$
+BEGIN
+    RAISE EXCEPTION 'the initial schema cannot be reverted';
+END
+$$;
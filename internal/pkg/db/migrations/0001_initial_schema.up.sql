@@ -0,0 +1,73 @@
+-- Baseline schema. Every statement is idempotent so that databases previously managed by
+-- GORM AutoMigrate can adopt versioned migrations without being rebuilt.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          text PRIMARY KEY,
+    first_name  text NOT NULL,
+    last_name   text NOT NULL,
+    email       text NOT NULL,
+    password    text NOT NULL,
+    role        varchar(20) NOT NULL DEFAULT 'attendee',
+    avatar_url  text DEFAULT NULL,
+    phone       text DEFAULT NULL,
+    locale      text NOT NULL DEFAULT 'en',
+    timezone    text NOT NULL DEFAULT 'UTC',
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL,
+    deleted_at  timestamptz DEFAULT NULL,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          text PRIMARY KEY,
+    user_id     text NOT NULL,
+    provider    text NOT NULL,
+    subject     text NOT NULL,
+    email       text NOT NULL,
+    created_at  timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_provider_subject ON user_identities (provider, subject);
+
+CREATE TABLE IF NOT EXISTS events (
+    id           text PRIMARY KEY,
+    name         text NOT NULL,
+    description  text NOT NULL,
+    location     text NOT NULL,
+    date         timestamptz NOT NULL,
+    user_id      text NOT NULL,
+    created_at   timestamptz NOT NULL,
+    updated_at   timestamptz NOT NULL,
+    deleted_at   timestamptz DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS event_members (
+    id          text PRIMARY KEY,
+    event_id    text NOT NULL,
+    email       text NOT NULL,
+    user_id     text,
+    role        varchar(20) NOT NULL,
+    status      varchar(20) NOT NULL,
+    invited_by  text NOT NULL,
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_members_event_email ON event_members (event_id, email);
+CREATE INDEX IF NOT EXISTS idx_event_members_user_id ON event_members (user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            text PRIMARY KEY,
+    user_id       text NOT NULL,
+    name          text NOT NULL,
+    prefix        text NOT NULL,
+    secret_hash   text NOT NULL,
+    scopes        text NOT NULL,
+    expires_at    timestamptz DEFAULT NULL,
+    last_used_at  timestamptz DEFAULT NULL,
+    created_at    timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
@@ -0,0 +1,4 @@
+-- Events carry a version that every update increments, so that updates can be made
+-- conditional on the version the client last read.
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- On databases created by 0001 these columns belong to the initial schema, so they are
+-- left in place.
@@ -0,0 +1,8 @@
+-- 0001 creates users with CREATE TABLE IF NOT EXISTS, which leaves a table created by GORM
+-- AutoMigrate as it was. Add the columns introduced since then to such tables.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'attendee';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url text DEFAULT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone text DEFAULT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale text NOT NULL DEFAULT 'en';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone text NOT NULL DEFAULT 'UTC';
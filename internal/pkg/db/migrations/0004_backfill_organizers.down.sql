@@ -0,0 +1,2 @@
+-- Which users were promoted is not recorded, and they may have created events as
+-- organizers since, so their roles are left as they are.
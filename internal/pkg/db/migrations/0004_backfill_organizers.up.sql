@@ -0,0 +1,9 @@
+-- Roles were introduced with every existing user defaulting to attendee, which took away
+-- the ability to create events from users who already had. Anyone who owns an event keeps
+-- it as an organizer. There is no admin until one is made with the admin command:
+--   admin user set-role -email EMAIL -role admin
+
+UPDATE users
+SET role = 'organizer'
+WHERE role = 'attendee'
+  AND id IN (SELECT user_id FROM events);
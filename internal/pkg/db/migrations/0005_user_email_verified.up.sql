@@ -0,0 +1,4 @@
+-- Users record when they last proved they receive mail at their address, since
+-- invitations and identity provider logins are matched to accounts by email.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz DEFAULT NULL;
@@ -0,0 +1,118 @@
+// Package i18n holds the message catalogue used for API responses and emails, and picks
+// the locale to use for a request.
+//
+// A request is answered in the first supported locale from its Accept-Language header,
+// then the caller's saved locale, then DefaultLocale. Emails use the recipient's saved
+// locale, or the request's locale when the recipient has no account yet. A message missing
+// from a locale's catalogue falls back to the DefaultLocale message.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const DefaultLocale = "en"
+
+// Locales are the supported locales, with DefaultLocale first.
+var Locales = []string{DefaultLocale, "fr", "pt"}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var (
+	catalogue = mustLoadCatalogue()
+	matcher   = newMatcher()
+)
+
+func mustLoadCatalogue() map[string]map[string]string {
+	catalogue := make(map[string]map[string]string, len(Locales))
+	for _, locale := range Locales {
+		data, err := localeFiles.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: reading %s catalogue: %v", locale, err))
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parsing %s catalogue: %v", locale, err))
+		}
+		catalogue[locale] = messages
+	}
+	return catalogue
+}
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, 0, len(Locales))
+	for _, locale := range Locales {
+		tags = append(tags, language.MustParse(locale))
+	}
+	return language.NewMatcher(tags)
+}
+
+// Match returns the supported locale for the first preference that has one, or
+// DefaultLocale. Each preference is an Accept-Language value or a single language tag, so
+// "pt-BR" and "fr-CH, fr;q=0.9" both match.
+func Match(preferences ...string) string {
+	for _, preference := range preferences {
+		if strings.TrimSpace(preference) == "" {
+			continue
+		}
+
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		_, index, confidence := matcher.Match(tags...)
+		if confidence != language.No {
+			return Locales[index]
+		}
+	}
+	return DefaultLocale
+}
+
+// Lookup returns the message for key in locale, falling back to DefaultLocale. It reports
+// false when neither catalogue has the key.
+func Lookup(locale, key string) (string, bool) {
+	if message, ok := catalogue[locale][key]; ok {
+		return message, true
+	}
+	message, ok := catalogue[DefaultLocale][key]
+	return message, ok
+}
+
+// Message returns the message for key in locale, or the key itself when it is missing
+// from the catalogue.
+func Message(locale, key string) string {
+	if message, ok := Lookup(locale, key); ok {
+		return message
+	}
+	return key
+}
+
+// T returns the message for key in the locale carried by ctx.
+func T(ctx context.Context, key string) string {
+	return Message(FromContext(ctx), key)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries locale.
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale stored in ctx, or DefaultLocale when there is none.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// GinMiddleware records request counts and latencies. Requests are labelled with the route
+// template rather than the raw path, so that IDs in URLs do not create unbounded series.
+func GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		HTTPRequestsInFlight.Inc()
+		// Deferred so that a panicking handler is not counted as in flight forever.
+		defer HTTPRequestsInFlight.Dec()
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(ctx.Writer.Status())
+		HTTPRequestsTotal.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		HTTPRequestDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the registry in the Prometheus exposition format. When token is set,
+// scrapers must send it as a bearer token.
+func Handler(token string) gin.HandlerFunc {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+
+	return func(ctx *gin.Context) {
+		if token != "" {
+			expected := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(ctx.GetHeader("Authorization")), []byte(expected)) != 1 {
+				ctx.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
@@ -1,55 +1,206 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
 	"strings"
+	"time"
 
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/rbac"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/denylist"
 	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtKey []byte) gin.HandlerFunc {
+var (
+	ErrAuthorizationMissing   = apperror.Unauthorized("authorization_missing", "Authorization Header is missing")
+	ErrAuthorizationMalformed = apperror.Unauthorized("authorization_malformed", "Invalid Authorization Header format")
+	ErrInvalidToken           = apperror.Unauthorized("invalid_token", "Invalid token")
+	ErrTokenRevoked           = apperror.Unauthorized("token_revoked", "Token has been revoked")
+	ErrInvalidAPIKey          = apperror.Unauthorized("invalid_api_key", "Invalid API key")
+	// ErrUnauthenticated is for handlers reached without an authenticated caller.
+	ErrUnauthenticated  = apperror.Unauthorized("unauthenticated", "Unauthorized")
+	ErrSessionRequired  = apperror.Forbidden("session_required", "This action cannot be performed with an API key")
+	ErrPermissionDenied = apperror.Forbidden("permission_denied", "You do not have permission to perform this action")
+	ErrScopeRequired    = apperror.Forbidden("api_key_scope_required", "This API key was not granted the scope this action requires")
+)
+
+// APIKeyIdentity is the owner of a valid API key and the scopes the key was granted.
+type APIKeyIdentity struct {
+	KeyID         string
+	UserID        string
+	Email         string
+	EmailVerified bool
+	Role          rbac.Role
+	Locale        string
+	Scopes        []rbac.Permission
+}
+
+// APIKeyAuthenticator resolves a raw API key, returning an error if it is unknown, expired
+// or belongs to a user who can no longer sign in.
+type APIKeyAuthenticator func(ctx context.Context, key string) (*APIKeyIdentity, error)
+
+// AuthMiddleware accepts either a bearer access token or an "ApiKey" credential.
+func AuthMiddleware(keySet *util.KeySet, denylistService *denylist.DenylistService, apiKeyAuthenticator APIKeyAuthenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
-			ctx.AbortWithStatusJSON(
-				HTTPException.NewUnauthorizedException("Authorization Header is missing", nil).StatusCode,
-				HTTPException.NewUnauthorizedException("Authorization Header is missing", nil).ToResponse(),
-			)
+			ctx.Error(ErrAuthorizationMissing)
+			ctx.Abort()
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "apikey" {
+			authenticateAPIKey(ctx, apiKeyAuthenticator, parts[1])
+			return
+		}
+
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			ctx.AbortWithStatusJSON(
-				HTTPException.NewUnauthorizedException("Invalid Authorization Header format", nil).StatusCode,
-				HTTPException.NewUnauthorizedException("Invalid Authorization Header format", nil).ToResponse(),
-			)
+			ctx.Error(ErrAuthorizationMalformed)
+			ctx.Abort()
 			return
 		}
 
 		tokenString := parts[1]
 
-		token, claims, err := util.ValidateToken(tokenString, jwtKey)
-		if err != nil || token == nil || !token.Valid {
-			ctx.AbortWithStatusJSON(
-				HTTPException.NewUnauthorizedException("Invalid token", nil).StatusCode,
-				HTTPException.NewUnauthorizedException("Invalid token", nil).ToResponse(),
-			)
+		token, claims, err := util.ValidateToken(tokenString, keySet)
+		if err != nil || token == nil || !token.Valid || claims["typ"] != "access" {
+			ctx.Error(ErrInvalidToken)
+			ctx.Abort()
 			return
 		}
 
 		userID, userIDExists := claims["userID"].(string)
 		if !userIDExists {
-			ctx.AbortWithStatusJSON(
-				HTTPException.NewUnauthorizedException("Token claims are missing required data", nil).StatusCode,
-				HTTPException.NewUnauthorizedException("Token claims are missing required data", nil).ToResponse(),
-			)
+			ctx.Error(ErrInvalidToken)
+			ctx.Abort()
+			return
+		}
+
+		tokenID, _ := claims["jti"].(string)
+		sessionID, _ := claims["sessionID"].(string)
+		issuedAt, _ := claims["iat"].(float64)
+
+		revoked, err := denylistService.IsRevoked(ctx, tokenID, sessionID, userID, time.UnixMilli(int64(math.Round(issuedAt*1000))))
+		if err != nil {
+			ctx.Error(fmt.Errorf("checking token denylist: %w", err))
+			ctx.Abort()
+			return
+		}
+
+		if revoked {
+			ctx.Error(ErrTokenRevoked)
+			ctx.Abort()
 			return
 		}
 
+		var permissions []rbac.Permission
+		if rawPermissions, ok := claims["permissions"].([]interface{}); ok {
+			for _, rawPermission := range rawPermissions {
+				if permission, ok := rawPermission.(string); ok {
+					permissions = append(permissions, rbac.Permission(permission))
+				}
+			}
+		}
+
 		ctx.Set("userID", userID)
+		addLoggerAttrs(ctx, slog.String("user_id", userID))
+		if email, ok := claims["email"].(string); ok {
+			ctx.Set("email", email)
+		}
+		if emailVerified, ok := claims["emailVerified"].(bool); ok {
+			ctx.Set("emailVerified", emailVerified)
+		}
+		if role, ok := claims["role"].(string); ok {
+			ctx.Set("role", rbac.Role(role))
+		}
+		if locale, ok := claims["locale"].(string); ok {
+			setLocale(ctx, locale)
+		}
+		ctx.Set("permissions", permissions)
+		if sessionID != "" {
+			ctx.Set("sessionID", sessionID)
+		}
 		ctx.Next()
 	}
 }
+
+// authenticateAPIKey sets up the context for a request made with an API key. The key acts
+// for its owner but only with the permissions that are both in its scopes and in the owner's
+// current role, so demoting a user also narrows every key they have issued.
+func authenticateAPIKey(ctx *gin.Context, apiKeyAuthenticator APIKeyAuthenticator, key string) {
+	identity, err := apiKeyAuthenticator(ctx, key)
+	if err != nil {
+		ctx.Error(ErrInvalidAPIKey)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set("userID", identity.UserID)
+	addLoggerAttrs(ctx, slog.String("user_id", identity.UserID), slog.String("api_key_id", identity.KeyID))
+	ctx.Set("email", identity.Email)
+	ctx.Set("emailVerified", identity.EmailVerified)
+	ctx.Set("role", identity.Role)
+	setLocale(ctx, identity.Locale)
+	ctx.Set("permissions", rbac.Intersect(identity.Role.Permissions(), identity.Scopes))
+	ctx.Set("scopes", identity.Scopes)
+	ctx.Set("apiKeyID", identity.KeyID)
+	ctx.Next()
+}
+
+// RequireSession must run after AuthMiddleware and rejects requests authenticated with an
+// API key, for account-security operations that must be performed by the user in person.
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("apiKeyID") != "" {
+			ctx.Error(ErrSessionRequired)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireScope must run after AuthMiddleware and rejects requests made with an API key that
+// was not granted the permission. Sessions are let through, for actions whose permission
+// comes from owning or belonging to a resource, which the service checks, rather than from a role.
+func RequireScope(permission rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("apiKeyID") != "" && !GetPrincipal(ctx).Can(permission) {
+			ctx.Error(ErrScopeRequired)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequirePermission must run after AuthMiddleware and rejects callers whose token does not grant the permission.
+func RequirePermission(permission rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !GetPrincipal(ctx).Can(permission) {
+			ctx.Error(ErrPermissionDenied)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// GetPrincipal returns the caller authenticated by AuthMiddleware.
+func GetPrincipal(ctx *gin.Context) rbac.Principal {
+	permissions, _ := ctx.Get("permissions")
+	grantedPermissions, _ := permissions.([]rbac.Permission)
+
+	return rbac.Principal{
+		UserID:        ctx.GetString("userID"),
+		Email:         ctx.GetString("email"),
+		EmailVerified: ctx.GetBool("emailVerified"),
+		Permissions:   grantedPermissions,
+	}
+}
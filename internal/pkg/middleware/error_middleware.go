@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorMiddleware writes the response for an error a handler reported with ctx.Error, so
+// that every handler maps service errors to status codes and error codes the same way.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		lastErr := ctx.Errors.Last()
+		if lastErr == nil || ctx.Writer.Written() {
+			return
+		}
+
+		exception := HTTPException.FromError(lastErr.Err)
+		if exception.StatusCode >= http.StatusInternalServerError {
+			// Neither the client nor the request log line sees the cause, so record it here.
+			GetLogger(ctx).Error("Request failed", slog.String("code", exception.Code), slog.Any("error", lastErr.Err))
+		}
+
+		if message, ok := i18n.Lookup(i18n.FromContext(ctx.Request.Context()), "error."+exception.Code); ok {
+			exception.Message = message
+		}
+		ctx.JSON(exception.StatusCode, exception.ToResponse())
+	}
+}
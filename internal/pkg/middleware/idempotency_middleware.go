@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	ErrIdempotencyKeyInvalid       = apperror.Validation("idempotency_key_invalid", "Idempotency-Key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused        = apperror.Validation("idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	ErrIdempotentRequestInProgress = apperror.Conflict("idempotent_request_in_progress", "A request with this Idempotency-Key is still being processed")
+	// ErrIdempotencyUnavailable is returned when the key store cannot be reached. The request
+	// is not processed, since it could not be protected against being processed twice.
+	ErrIdempotencyUnavailable = apperror.Unavailable("idempotency_unavailable", "Request could not be processed, please try again")
+)
+
+// IdempotencyMiddleware lets clients retry a request safely by sending the same
+// Idempotency-Key header. The first successful response to a key is stored and replayed
+// to every retry, and a retry that arrives while the first request is still running is
+// rejected with a conflict. Error responses are not stored, so a failed request can be
+// retried with its key. Keys are scoped to the caller, so it must run after AuthMiddleware.
+func IdempotencyMiddleware(idempotencyService *idempotency.IdempotencyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if !isValidIdempotencyKey(key) {
+			ctx.Error(ErrIdempotencyKeyInvalid)
+			ctx.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.Error(fmt.Errorf("reading request body: %w", err))
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := ctx.GetString("userID") + ":" + key
+		fingerprint := requestFingerprint(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		claim, existing, err := idempotencyService.Begin(ctx, storeKey, fingerprint)
+		if err != nil {
+			ctx.Error(ErrIdempotencyUnavailable.WithCause(err))
+			ctx.Abort()
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				ctx.Error(ErrIdempotencyKeyReused)
+			case existing.Status == idempotency.StatusInProgress:
+				ctx.Header("Retry-After", "1")
+				ctx.Error(ErrIdempotentRequestInProgress)
+			default:
+				replayResponse(ctx, existing)
+			}
+			ctx.Abort()
+			return
+		}
+
+		writer := &recordingResponseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// The client may have given up on the request, but its response must still be stored
+		// for the retry.
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+
+		// Errors reported with ctx.Error are written by ErrorMiddleware after this returns.
+		if len(ctx.Errors) > 0 || !writer.Written() || writer.Status() >= http.StatusInternalServerError {
+			if err := idempotencyService.Release(storeCtx, storeKey, claim); err != nil {
+				GetLogger(ctx).Warn("Failed to release idempotency key", slog.Any("error", err))
+			}
+			return
+		}
+
+		record := idempotency.Record{
+			Fingerprint: fingerprint,
+			StatusCode:  writer.Status(),
+			Header:      writer.Header().Clone(),
+			Body:        writer.body.Bytes(),
+		}
+		err = idempotencyService.Complete(storeCtx, storeKey, claim, record)
+		switch {
+		case errors.Is(err, idempotency.ErrClaimLost):
+			GetLogger(ctx).Warn("Idempotency key was claimed again before the response was stored")
+		case err != nil:
+			GetLogger(ctx).Error("Failed to store idempotent response", slog.Any("error", err))
+		}
+	}
+}
+
+// replayResponse writes a stored response with its headers, apart from the request ID,
+// which stays this request's own.
+func replayResponse(ctx *gin.Context, record *idempotency.Record) {
+	header := ctx.Writer.Header()
+	for name, values := range record.Header {
+		if name != http.CanonicalHeaderKey(RequestIDHeader) {
+			header[name] = values
+		}
+	}
+	header.Set(idempotentReplayedHeader, "true")
+
+	ctx.Status(record.StatusCode)
+	ctx.Writer.Write(record.Body)
+}
+
+// requestFingerprint identifies a request by its method, path and body, so that a key
+// reused for a different request is detected.
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < ' ' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingResponseWriter keeps a copy of the response body.
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
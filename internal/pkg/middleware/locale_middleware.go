@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware picks the locale for the response from the Accept-Language header.
+// AuthMiddleware falls back to the caller's saved locale when the header names no
+// supported locale.
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Vary", "Accept-Language")
+		setLocale(ctx, "")
+		ctx.Next()
+	}
+}
+
+// setLocale stores the negotiated locale in the request's context, where handlers and
+// services look for it.
+func setLocale(ctx *gin.Context, savedLocale string) {
+	locale := i18n.Match(ctx.GetHeader("Accept-Language"), savedLocale)
+	ctx.Request = ctx.Request.WithContext(i18n.NewContext(ctx.Request.Context(), locale))
+	ctx.Header("Content-Language", locale)
+}
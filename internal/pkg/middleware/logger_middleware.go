@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LoggerMiddleware gives every request a logger that carries its request ID, route and
+// trace ID, and logs one line per request when it completes. It must run after
+// RequestIDMiddleware.
+func LoggerMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		requestLogger := logger.With(
+			slog.String("request_id", GetRequestID(ctx)),
+			slog.String("route", route),
+		)
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			requestLogger = requestLogger.With(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		setRequestLogger(ctx, requestLogger)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		// The query string is left out as it can carry codes and tokens.
+		GetLogger(ctx).LogAttrs(ctx, level, "Request completed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("response_size", ctx.Writer.Size()),
+			slog.String("client_ip", ctx.ClientIP()),
+		)
+	}
+}
+
+// GetLogger returns the request's logger, or the default logger outside a request.
+func GetLogger(ctx *gin.Context) *slog.Logger {
+	return logging.FromContext(ctx.Request.Context(), slog.Default())
+}
+
+// addLoggerAttrs adds attributes to the request's logger for the rest of the request, for
+// example the user once they have been authenticated.
+func addLoggerAttrs(ctx *gin.Context, args ...any) {
+	setRequestLogger(ctx, GetLogger(ctx).With(args...))
+}
+
+// setRequestLogger stores the logger in the request's context, which is where services
+// look for it, rather than in the gin keys.
+func setRequestLogger(ctx *gin.Context, logger *slog.Logger) {
+	ctx.Request = ctx.Request.WithContext(logging.NewContext(ctx.Request.Context(), logger))
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/service/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrRateLimited = apperror.RateLimited("rate_limited", "Too many requests, please try again later")
+
+// RateLimitMiddleware allows each client IP at most limit requests per window to the routes
+// it guards, counted together under name. Requests are let through when the counter store
+// cannot be reached, so that an outage does not lock everyone out.
+func RateLimitMiddleware(rateLimitService *ratelimit.RateLimitService, name string, limit int64, window time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowed, retryAfter, err := rateLimitService.Allow(ctx, name+":"+ctx.ClientIP(), limit, window)
+		if err != nil {
+			GetLogger(ctx).Warn("Failed to check rate limit", slog.String("limit", name), slog.Any("error", err))
+			ctx.Next()
+			return
+		}
+
+		if !allowed {
+			ctx.Header("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+			ctx.Error(ErrRateLimited)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -1,27 +1,27 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
 	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RecoveryMiddleware turns a panic into a 500 response. Handlers report expected failures
+// with ctx.Error instead, see ErrorMiddleware.
 func RecoveryMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("Panic recovered: %v", r)
-
-				if httpErr, ok := r.(*HTTPException.HTTPException); ok {
-					ctx.JSON(httpErr.StatusCode, httpErr.ToResponse())
-					return
-				}
+				GetLogger(ctx).Error("Panic recovered", slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
 
 				exception := HTTPException.NewInternalServerException(nil)
-				ctx.JSON(http.StatusInternalServerError, exception.ToResponse())
+				exception.Message = i18n.T(ctx.Request.Context(), "error."+exception.Code)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, exception.ToResponse())
 			}
 		}()
 		ctx.Next()
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware keeps the request ID set by a proxy or client, or assigns one, and
+// echoes it in the response so that a user's report can be matched to the server's logs.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = util.GenerateUUID()
+		}
+
+		ctx.Set("requestID", requestID)
+		ctx.Header(RequestIDHeader, requestID)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", requestID))
+		ctx.Next()
+	}
+}
+
+// isValidRequestID rejects IDs that could be used to forge or bloat log lines.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+
+	for _, r := range requestID {
+		isAlphanumeric := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlphanumeric && r != '-' && r != '_' && r != '.' && r != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRequestID returns the ID assigned by RequestIDMiddleware.
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString("requestID")
+}
@@ -0,0 +1,301 @@
+// Package openapi builds the OpenAPI 3.1 document for the API from route descriptions kept
+// next to each module's routes. Request and response schemas are generated from the Go
+// types the handlers use, so they follow the DTOs as they change.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Security is the kind of credential a route requires.
+type Security int
+
+const (
+	SecurityNone Security = iota
+	// SecuritySession requires a bearer access token; API keys are rejected.
+	SecuritySession
+	// SecurityAny accepts a bearer access token or an API key.
+	SecurityAny
+)
+
+// Data describes the data field of the response envelope, keyed by the names the handler
+// uses, for example Data{"event": Event{}}.
+type Data map[string]interface{}
+
+// Parameter is a query, header or cookie parameter. Path parameters are taken from the path.
+type Parameter struct {
+	In          string
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Operation describes one route.
+type Operation struct {
+	Method string
+	// Path uses gin's syntax, relative to the group the module's routes are registered on.
+	Path        string
+	Summary     string
+	Description string
+	Security    Security
+	Parameters  []Parameter
+	// Body is a value of the request body's type, or nil when the route takes no body.
+	Body interface{}
+	// Status is the success status code, http.StatusOK when zero.
+	Status int
+	// Data is the data in the response envelope, or nil when the envelope has none.
+	Data Data
+	// Raw is a value of the response body's type for handlers that do not use the envelope.
+	Raw interface{}
+	// ContentType is set for success responses that are not JSON, such as file downloads.
+	ContentType string
+}
+
+// Document is an OpenAPI document under construction.
+type Document struct {
+	info    map[string]interface{}
+	paths   map[string]map[string]interface{}
+	routes  map[string]bool
+	tags    []map[string]interface{}
+	schemas *schemaRegistry
+}
+
+func New(title, version string) *Document {
+	document := &Document{
+		info:    map[string]interface{}{"title": title, "version": version},
+		paths:   make(map[string]map[string]interface{}),
+		routes:  make(map[string]bool),
+		schemas: newSchemaRegistry(),
+	}
+	document.schemas.add("FieldError", fieldErrorSchema)
+	document.schemas.add("ErrorResponse", errorResponseSchema)
+	return document
+}
+
+// Add documents operations registered under prefix, grouped under tag.
+func (d *Document) Add(prefix, tag string, operations []Operation) {
+	if !d.hasTag(tag) {
+		d.tags = append(d.tags, map[string]interface{}{"name": tag})
+	}
+
+	for _, operation := range operations {
+		route := prefix + operation.Path
+		d.routes[operation.Method+" "+route] = true
+
+		path, pathParameters := toOpenAPIPath(route)
+		if d.paths[path] == nil {
+			d.paths[path] = make(map[string]interface{})
+		}
+		d.paths[path][strings.ToLower(operation.Method)] = d.operation(tag, route, operation, pathParameters)
+	}
+}
+
+func (d *Document) hasTag(tag string) bool {
+	for _, existing := range d.tags {
+		if existing["name"] == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) operation(tag, route string, operation Operation, pathParameters []string) map[string]interface{} {
+	result := map[string]interface{}{
+		"tags":        []string{tag},
+		"summary":     operation.Summary,
+		"operationId": operationID(operation.Method, route),
+		"responses":   d.responses(operation),
+	}
+	if operation.Description != "" {
+		result["description"] = operation.Description
+	}
+
+	parameters := make([]map[string]interface{}, 0, len(pathParameters)+len(operation.Parameters))
+	for _, name := range pathParameters {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, parameter := range operation.Parameters {
+		entry := map[string]interface{}{
+			"name": parameter.Name, "in": parameter.In, "required": parameter.Required,
+			"schema": map[string]interface{}{"type": "string"},
+		}
+		if parameter.Description != "" {
+			entry["description"] = parameter.Description
+		}
+		parameters = append(parameters, entry)
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	if operation.Body != nil {
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": d.schemas.schemaFor(reflect.TypeOf(operation.Body))},
+			},
+		}
+	}
+
+	switch operation.Security {
+	case SecuritySession:
+		result["security"] = []map[string][]string{{"bearerAuth": {}}}
+	case SecurityAny:
+		result["security"] = []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+	}
+
+	return result
+}
+
+func (d *Document) responses(operation Operation) map[string]interface{} {
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case operation.ContentType != "":
+		success["content"] = map[string]interface{}{operation.ContentType: map[string]interface{}{}}
+	case operation.Raw != nil:
+		success["content"] = jsonContent(d.schemas.schemaFor(reflect.TypeOf(operation.Raw)))
+	case status >= 300 && status < 400:
+		// Redirects have no body.
+	default:
+		success["content"] = jsonContent(d.envelopeSchema(operation.Data))
+	}
+
+	responses := map[string]interface{}{
+		strconv.Itoa(status): success,
+		"default":            errorResponse("Error"),
+	}
+	if operation.Body != nil {
+		responses["400"] = errorResponse("Validation failed; errors lists a FieldError for each failed rule")
+	}
+	if operation.Security != SecurityNone {
+		responses["401"] = errorResponse("Missing or invalid credentials")
+	}
+	return responses
+}
+
+func (d *Document) envelopeSchema(data Data) map[string]interface{} {
+	properties := map[string]interface{}{
+		"success": map[string]interface{}{"type": "boolean", "const": true},
+		"message": map[string]interface{}{"type": "string", "description": "Localized from Accept-Language"},
+	}
+
+	if len(data) > 0 {
+		dataProperties := make(map[string]interface{}, len(data))
+		for name, value := range data {
+			dataProperties[name] = d.schemas.schemaFor(reflect.TypeOf(value))
+		}
+		properties["data"] = map[string]interface{}{"type": "object", "properties": dataProperties}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"success", "message"},
+		"properties": properties,
+	}
+}
+
+// MarshalJSON renders the document.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"openapi": "3.1.0",
+		"info":    d.info,
+		"tags":    d.tags,
+		"paths":   d.paths,
+		"components": map[string]interface{}{
+			"schemas": d.schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"apiKeyAuth": map[string]interface{}{
+					"type": "apiKey", "in": "header", "name": "Authorization",
+					"description": `An API key sent as "ApiKey ebk_<prefix>_<secret>"`,
+				},
+			},
+		},
+	})
+}
+
+// Verify reports routes that are registered but not documented, or documented but not
+// registered, so the document cannot silently fall behind the router.
+func (d *Document) Verify(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool, len(routes))
+	var problems []string
+
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !d.routes[key] {
+			problems = append(problems, "undocumented route "+key)
+		}
+	}
+	for key := range d.routes {
+		if !registered[key] {
+			problems = append(problems, "documented route is not registered: "+key)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("OpenAPI document does not match the router: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Handler serves the document. It must be called after every operation has been added.
+func (d *Document) Handler() (gin.HandlerFunc, error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json", body)
+	}, nil
+}
+
+// toOpenAPIPath converts gin's ":id" parameters to "{id}" and returns their names.
+func toOpenAPIPath(route string) (string, []string) {
+	segments := strings.Split(route, "/")
+	var parameters []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			parameters = append(parameters, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// operationID derives an ID from the method and route, such as "get_api_events_id".
+func operationID(method, route string) string {
+	words := []string{strings.ToLower(method)}
+	for _, segment := range strings.FieldsFunc(route, func(r rune) bool { return r == '/' || r == '-' || r == '.' }) {
+		words = append(words, strings.ToLower(strings.TrimPrefix(segment, ":")))
+	}
+	return strings.Join(words, "_")
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"}),
+	}
+}
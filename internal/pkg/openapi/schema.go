@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldErrorSchema and errorResponseSchema describe the body HTTPException writes.
+var (
+	fieldErrorSchema = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"field", "rule", "message"},
+		"properties": map[string]interface{}{
+			"field":   map[string]interface{}{"type": "string", "examples": []string{"email"}},
+			"rule":    map[string]interface{}{"type": "string", "examples": []string{"required"}},
+			"param":   map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string", "examples": []string{"email is a required field"}},
+		},
+	}
+
+	errorResponseSchema = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"success", "statusCode", "code", "message"},
+		"properties": map[string]interface{}{
+			"success":    map[string]interface{}{"type": "boolean", "const": false},
+			"statusCode": map[string]interface{}{"type": "integer"},
+			"code": map[string]interface{}{
+				"type":        "string",
+				"description": "Stable, machine-readable error code",
+				"examples":    []string{"event_not_found"},
+			},
+			"message": map[string]interface{}{"type": "string", "description": "Localized from Accept-Language"},
+			"errors": map[string]interface{}{
+				"description": "Details of the error; a list of FieldError when code is validation_failed",
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/FieldError"}},
+					map[string]interface{}{},
+				},
+			},
+		},
+	}
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRegistry generates JSON schemas from Go types. Named structs become components so
+// each is described once and referenced wherever it is used.
+type schemaRegistry struct {
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		components: make(map[string]interface{}),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+func (r *schemaRegistry) add(name string, schema map[string]interface{}) {
+	r.components[name] = schema
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return map[string]interface{}{"$ref": "#/components/schemas/" + r.component(t)}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return r.structSchema(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.schemaFor(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	default:
+		// Interfaces can hold anything.
+		return map[string]interface{}{}
+	}
+}
+
+// component registers a named struct and returns its component name. Types from different
+// packages that share a name are told apart by their package name.
+func (r *schemaRegistry) component(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := r.components[name]; taken {
+		packageName := path.Base(t.PkgPath())
+		name = strings.ToUpper(packageName[:1]) + packageName[1:] + name
+	}
+
+	r.names[t] = name
+	// Reserve the name before generating the schema, in case the type refers to itself.
+	r.components[name] = nil
+	r.components[name] = r.structSchema(t)
+	return name
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := r.schemaFor(field.Type)
+		rules := field.Tag.Get("validate")
+		applyRules(schema, rules)
+		properties[name] = schema
+
+		if isRequired(field, rules, options) {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// isRequired reads request fields' validate rules. Fields without rules are response fields,
+// which are always present unless they are omitted when empty.
+func isRequired(field reflect.StructField, rules, jsonOptions string) bool {
+	if rules != "" {
+		for _, rule := range strings.Split(rules, ",") {
+			if rule == "dive" {
+				break
+			}
+			if rule == "required" {
+				return true
+			}
+		}
+		return false
+	}
+
+	return field.Type.Kind() != reflect.Pointer && !strings.Contains(jsonOptions, "omitempty")
+}
+
+// applyRules adds the schema keywords that match a field's validate rules. Rules after
+// "dive" apply to the elements of a slice and are left out.
+func applyRules(schema map[string]interface{}, rules string) {
+	if rules == "" || schema["$ref"] != nil {
+		return
+	}
+
+	isString := schema["type"] == "string"
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "e164":
+			schema["pattern"] = `^\+[1-9][0-9]{1,14}$`
+		case "timezone":
+			schema["description"] = "IANA time zone, such as Europe/Paris"
+		case "bcp47_language_tag":
+			schema["description"] = "BCP 47 language tag, such as fr or pt-BR"
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "min", "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			keyword := name + "Items"
+			if isString {
+				keyword = name + "Length"
+			}
+			schema[keyword] = limit
+		}
+	}
+}
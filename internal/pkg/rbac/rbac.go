@@ -0,0 +1,94 @@
+package rbac
+
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleOrganizer Role = "organizer"
+	RoleAttendee  Role = "attendee"
+)
+
+type Permission string
+
+const (
+	PermissionCreateEvents   Permission = "events:create"
+	PermissionManageAnyEvent Permission = "events:manage_any"
+	PermissionManageUsers    Permission = "users:manage"
+	// PermissionManageEvents lets an API key update, delete and manage the members of the
+	// events its owner owns or helps run. Every role has it; which events it applies to is
+	// decided by ownership and membership.
+	PermissionManageEvents Permission = "events:manage"
+)
+
+var allPermissions = []Permission{
+	PermissionCreateEvents,
+	PermissionManageAnyEvent,
+	PermissionManageUsers,
+	PermissionManageEvents,
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionCreateEvents,
+		PermissionManageAnyEvent,
+		PermissionManageUsers,
+		PermissionManageEvents,
+	},
+	RoleOrganizer: {
+		PermissionCreateEvents,
+		PermissionManageEvents,
+	},
+	RoleAttendee: {
+		PermissionManageEvents,
+	},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range allPermissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersect returns the permissions present in both lists, in the order of the first.
+func Intersect(permissions, allowed []Permission) []Permission {
+	result := make([]Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		for _, candidate := range allowed {
+			if candidate == permission {
+				result = append(result, permission)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Principal is the authenticated caller and the permissions granted to it for the current request.
+type Principal struct {
+	UserID string
+	Email  string
+	// EmailVerified reports whether the user has proved they receive mail at Email.
+	EmailVerified bool
+	Permissions   []Permission
+}
+
+func (p Principal) Can(permission Permission) bool {
+	for _, granted := range p.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,5 @@ func NewRedisClient(addr string) (*redis.Client, error) {
 		return nil, fmt.Errorf("could not connect to Redis at %s: %w", addr, err)
 	}
 
-	fmt.Println("Connected to Redis successfully!")
 	return client, nil
 }
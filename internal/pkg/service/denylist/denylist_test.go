@@ -0,0 +1,35 @@
+package denylist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRevokeUserTokensKeepsTokensIssuedAfterward(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	service := NewDenylistService(client)
+
+	before := time.Now().Add(-time.Millisecond)
+	if err := service.RevokeUserTokens(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Add(time.Millisecond)
+
+	if revoked, err := service.IsRevoked(ctx, "", "", "u1", before); err != nil || !revoked {
+		t.Fatalf("token issued before the revocation: got %v, %v", revoked, err)
+	}
+	// A client refreshing straight away usually gets a token within the same second.
+	if revoked, err := service.IsRevoked(ctx, "", "", "u1", after); err != nil || revoked {
+		t.Fatalf("token issued after the revocation: got %v, %v", revoked, err)
+	}
+	if revoked, err := service.IsRevoked(ctx, "", "", "u2", before); err != nil || revoked {
+		t.Fatalf("token of another user: got %v, %v", revoked, err)
+	}
+}
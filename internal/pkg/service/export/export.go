@@ -0,0 +1,429 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	jobKeyPrefix      = "data_export:"
+	userJobsKeyPrefix = "data_exports:"
+	queueKey          = "data_export_queue"
+	// processingKey lists the jobs taken off the queue by a worker and not yet finished.
+	processingKey  = "data_export_queue:processing"
+	claimKeyPrefix = "data_export_claim:"
+
+	// jobExpiresAt is how long a finished archive stays available for download.
+	jobExpiresAt = 24 * time.Hour
+	// processTimeout bounds how long a job may run.
+	processTimeout = 5 * time.Minute
+	// claimTimeout is how long after being taken a job is considered abandoned, because the
+	// worker running it has stopped, and is queued again.
+	claimTimeout = processTimeout + time.Minute
+	// pollInterval is how long the worker waits before checking an empty queue again.
+	pollInterval = 2 * time.Second
+)
+
+var ErrJobNotFound = apperror.NotFound("data_export_not_found", "Data export not found")
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+type Job struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      JobStatus  `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+// Collector gathers a user's data as a set of named documents, each written to the
+// archive as its own JSON file.
+type Collector func(ctx context.Context, userID string) (map[string]interface{}, error)
+
+// ExportService assembles data-export archives in the background. Job state and the queue
+// live in Redis, so any replica can report on a job or run it and a job queued before a
+// restart is not lost; archives are written to dir, which must be shared between replicas
+// for downloads to work from all of them.
+type ExportService struct {
+	client    *redis.Client
+	dir       string
+	collector Collector
+	wg        sync.WaitGroup
+	stopOnce  sync.Once
+	done      chan struct{}
+	logger    *slog.Logger
+}
+
+func NewExportService(client *redis.Client, dir string, logger *slog.Logger) (*ExportService, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &ExportService{
+		client: client,
+		dir:    dir,
+		done:   make(chan struct{}),
+		logger: logger,
+	}, nil
+}
+
+// Start launches the worker that processes queued exports with the given collector.
+func (s *ExportService) Start(collector Collector) {
+	s.collector = collector
+
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop stops accepting work and waits for the export in progress, if any, to finish.
+func (s *ExportService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.done) })
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ExportService) Enqueue(ctx context.Context, jobID, userID string) (*Job, error) {
+	now := time.Now().UTC()
+	job := Job{
+		ID:        jobID,
+		UserID:    userID,
+		Status:    JobStatusPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(jobExpiresAt),
+	}
+
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, jobKeyPrefix+job.ID, jobJSON, time.Until(job.ExpiresAt))
+	pipe.SAdd(ctx, userJobsKeyPrefix+job.UserID, job.ID)
+	pipe.Expire(ctx, userJobsKeyPrefix+job.UserID, jobExpiresAt)
+	pipe.RPush(ctx, queueKey, job.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// GetJob returns the export if it exists and belongs to the user.
+func (s *ExportService) GetJob(ctx context.Context, userID, jobID string) (*Job, error) {
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil || job.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// loadJob returns the job, or nil if it has expired or been deleted.
+func (s *ExportService) loadJob(ctx context.Context, jobID string) (*Job, error) {
+	jobJSON, err := s.client.Get(ctx, jobKeyPrefix+jobID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(jobJSON), &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (s *ExportService) ArchivePath(jobID string) string {
+	return filepath.Join(s.dir, jobID+".zip")
+}
+
+// DeleteUserJobs removes every export belonging to the user, including archives on disk.
+func (s *ExportService) DeleteUserJobs(ctx context.Context, userID string) error {
+	jobIDs, err := s.client.SMembers(ctx, userJobsKeyPrefix+userID).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{userJobsKeyPrefix + userID}
+	for _, jobID := range jobIDs {
+		keys = append(keys, jobKeyPrefix+jobID)
+
+		if err := os.Remove(s.ArchivePath(jobID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// finishJob stores the outcome of a job. It reports false when the job no longer exists
+// because its user's exports were deleted while it ran, and leaves it deleted.
+func (s *ExportService) finishJob(ctx context.Context, job Job) (bool, error) {
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.client.SetArgs(ctx, jobKeyPrefix+job.ID, jobJSON, redis.SetArgs{Mode: "XX", TTL: time.Until(job.ExpiresAt)}).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *ExportService) run() {
+	defer s.wg.Done()
+
+	s.requeueAbandonedJobs()
+
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-cleanupTicker.C:
+			s.removeExpiredArchives()
+			s.requeueAbandonedJobs()
+		default:
+		}
+
+		jobID, err := s.takeJob(context.Background())
+		if err != nil {
+			if err != redis.Nil {
+				s.logger.Error("Failed to take data export from queue", slog.Any("error", err))
+			}
+			select {
+			case <-s.done:
+				return
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		s.processQueued(jobID)
+	}
+}
+
+// takeJobScript moves the next job to the processing list and claims it in one step, so
+// that requeueAbandonedJobs never sees a job a worker has just taken without its claim.
+var takeJobScript = redis.NewScript(`
+local jobID = redis.call("LMOVE", KEYS[1], KEYS[2], "LEFT", "RIGHT")
+if not jobID then
+	return false
+end
+redis.call("SET", ARGV[1] .. jobID, 1, "PX", ARGV[2])
+return jobID
+`)
+
+// takeJob takes the next job off the queue, or returns redis.Nil when the queue is empty.
+func (s *ExportService) takeJob(ctx context.Context) (string, error) {
+	return takeJobScript.Run(ctx, s.client, []string{queueKey, processingKey},
+		claimKeyPrefix, claimTimeout.Milliseconds()).Text()
+}
+
+// processQueued runs a job taken off the queue, unless it has been deleted or has already
+// run, and then removes it from the jobs being processed.
+func (s *ExportService) processQueued(jobID string) {
+	ctx := context.Background()
+	logger := s.logger.With(slog.String("export_id", jobID))
+
+	defer func() {
+		pipe := s.client.TxPipeline()
+		pipe.LRem(ctx, processingKey, 1, jobID)
+		pipe.Del(ctx, claimKeyPrefix+jobID)
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.Error("Failed to remove data export from queue", slog.Any("error", err))
+		}
+	}()
+
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		logger.Error("Failed to load data export", slog.Any("error", err))
+		return
+	}
+
+	if job == nil || job.Status != JobStatusPending {
+		return
+	}
+
+	s.process(*job)
+}
+
+// requeueJobScript puts a job back on the queue if it is still being processed but its
+// claim has expired, atomically so that two replicas cannot both requeue it.
+var requeueJobScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[3]) == 1 then
+	return 0
+end
+if redis.call("LREM", KEYS[2], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call("RPUSH", KEYS[1], ARGV[1])
+return 1
+`)
+
+// requeueAbandonedJobs queues again the jobs whose worker stopped before finishing them.
+func (s *ExportService) requeueAbandonedJobs() {
+	ctx := context.Background()
+
+	jobIDs, err := s.client.LRange(ctx, processingKey, 0, -1).Result()
+	if err != nil {
+		s.logger.Error("Failed to list data exports in progress", slog.Any("error", err))
+		return
+	}
+
+	for _, jobID := range jobIDs {
+		requeued, err := requeueJobScript.Run(ctx, s.client, []string{queueKey, processingKey, claimKeyPrefix + jobID}, jobID).Int()
+		if err != nil {
+			s.logger.Error("Failed to requeue data export", slog.String("export_id", jobID), slog.Any("error", err))
+			continue
+		}
+		if requeued == 1 {
+			s.logger.Warn("Requeued abandoned data export", slog.String("export_id", jobID))
+		}
+	}
+}
+
+func (s *ExportService) process(job Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	// Jobs run outside any request, so each one starts its own trace.
+	ctx, span := tracing.Tracer().Start(ctx, "export.process", trace.WithAttributes(attribute.String("export.id", job.ID)))
+	defer span.End()
+
+	job.Status = JobStatusCompleted
+	if err := s.writeArchive(ctx, job); err != nil {
+		s.logger.Error("Data export failed", slog.String("export_id", job.ID), slog.String("user_id", job.UserID), slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		job.Status = JobStatusFailed
+	}
+
+	completedAt := time.Now().UTC()
+	job.CompletedAt = &completedAt
+
+	saved, err := s.finishJob(ctx, job)
+	if err != nil {
+		s.logger.Error("Failed to save data export", slog.String("export_id", job.ID), slog.Any("error", err))
+		return
+	}
+
+	// The user's exports were deleted while this one ran, so its archive must not outlive them.
+	if !saved {
+		if err := os.Remove(s.ArchivePath(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error("Failed to remove data export", slog.String("export_id", job.ID), slog.Any("error", err))
+		}
+	}
+}
+
+func (s *ExportService) writeArchive(ctx context.Context, job Job) error {
+	documents, err := s.collector(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.ArchivePath(job.ID) + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(file)
+	for _, name := range names {
+		writer, err := archive.Create(name + ".json")
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(documents[name]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.ArchivePath(job.ID))
+}
+
+func (s *ExportService) removeExpiredArchives() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Error("Failed to list data export archives", slog.Any("error", err))
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < jobExpiresAt {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			s.logger.Error("Failed to remove expired data export", slog.String("file", entry.Name()), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestService(t *testing.T) (*ExportService, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	service, err := NewExportService(client, t.TempDir(), slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, server
+}
+
+func TestTakenJobIsClaimedAndNotRequeued(t *testing.T) {
+	ctx := context.Background()
+	service, server := newTestService(t)
+
+	if _, err := service.Enqueue(ctx, "job-1", "u1"); err != nil {
+		t.Fatal(err)
+	}
+
+	jobID, err := service.takeJob(ctx)
+	if err != nil || jobID != "job-1" {
+		t.Fatalf("takeJob: got %q, %v", jobID, err)
+	}
+	if !server.Exists(claimKeyPrefix + "job-1") {
+		t.Fatal("the job was taken without being claimed")
+	}
+
+	service.requeueAbandonedJobs()
+	if queued, _ := server.List(queueKey); len(queued) != 0 {
+		t.Fatalf("a claimed job was requeued: queue is %v", queued)
+	}
+
+	if _, err := service.takeJob(ctx); err != redis.Nil {
+		t.Fatalf("takeJob on an empty queue: got %v, want redis.Nil", err)
+	}
+}
+
+func TestAbandonedJobIsRequeued(t *testing.T) {
+	ctx := context.Background()
+	service, server := newTestService(t)
+
+	if _, err := service.Enqueue(ctx, "job-1", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.takeJob(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	server.FastForward(claimTimeout + time.Second)
+	service.requeueAbandonedJobs()
+
+	queued, _ := server.List(queueKey)
+	processing, _ := server.List(processingKey)
+	if len(queued) != 1 || queued[0] != "job-1" || len(processing) != 0 {
+		t.Fatalf("got queue %v and processing %v, want the job back on the queue only", queued, processing)
+	}
+}
+
+func TestWorkerCompletesQueuedJob(t *testing.T) {
+	ctx := context.Background()
+	service, server := newTestService(t)
+
+	if _, err := service.Enqueue(ctx, "job-1", "u1"); err != nil {
+		t.Fatal(err)
+	}
+
+	service.Start(func(ctx context.Context, userID string) (map[string]interface{}, error) {
+		return map[string]interface{}{"profile": map[string]string{"id": userID}}, nil
+	})
+	t.Cleanup(func() { service.Stop(context.Background()) })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := service.GetJob(ctx, "u1", "job-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == JobStatusCompleted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(service.ArchivePath("job-1")); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if processing, _ := server.List(processingKey); len(processing) != 0 || server.Exists(claimKeyPrefix+"job-1") {
+		t.Fatalf("finished job still in progress: processing %v", processing)
+	}
+}
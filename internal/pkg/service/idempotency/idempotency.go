@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/util"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "idempotency:"
+
+	// retentionPeriod is how long a completed response is replayed for its key.
+	retentionPeriod = 24 * time.Hour
+	// lockTimeout bounds how long a request holds its key while it runs, so a key whose
+	// request crashed is eventually freed.
+	lockTimeout = time.Minute
+)
+
+// ErrClaimLost is returned by Complete when the claim expired and the key was claimed
+// again by a retry, whose record is left in place.
+var ErrClaimLost = errors.New("idempotency key claim was lost")
+
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// Record is what is kept for an idempotency key: the fingerprint of the request that
+// first used it and, once that request has completed, its response. Claim identifies the
+// request holding the key while it is in progress.
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      Status      `json:"status"`
+	Claim       string      `json:"claim,omitempty"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyService stores the first response to each idempotency key so that a retried
+// request gets the same response instead of being processed again.
+type IdempotencyService struct {
+	client *redis.Client
+}
+
+func NewIdempotencyService(client *redis.Client) *IdempotencyService {
+	return &IdempotencyService{client: client}
+}
+
+// Begin claims key for a request with fingerprint. When the claim succeeded it returns the
+// claim token to pass to Complete or Release, and the request should run. Otherwise it
+// returns the existing record of the key.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (string, *Record, error) {
+	claim := util.GenerateUUID()
+	record, err := json.Marshal(Record{Fingerprint: fingerprint, Status: StatusInProgress, Claim: claim})
+	if err != nil {
+		return "", nil, err
+	}
+
+	claimed, err := s.client.SetNX(ctx, keyPrefix+key, record, lockTimeout).Result()
+	if err != nil {
+		return "", nil, err
+	}
+	if claimed {
+		return claim, nil, nil
+	}
+
+	existing, err := s.get(ctx, key)
+	if err != nil || existing != nil {
+		return "", existing, err
+	}
+
+	// The key expired between the two calls, so try again.
+	return s.Begin(ctx, key, fingerprint)
+}
+
+// completeScript stores a response only while the key is still held by the claim that
+// ran the request. A request that outlived lockTimeout must not overwrite the key of the
+// retry that claimed it next.
+var completeScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current or cjson.decode(current)["claim"] ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// releaseScript deletes the key only while it is still held by the claim.
+var releaseScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current or cjson.decode(current)["claim"] ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// Complete stores the response to the request that claimed key with claim. It returns
+// ErrClaimLost when the key is no longer held by claim.
+func (s *IdempotencyService) Complete(ctx context.Context, key, claim string, record Record) error {
+	record.Status = StatusCompleted
+	record.Claim = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	stored, err := completeScript.Run(ctx, s.client, []string{keyPrefix + key},
+		claim, data, retentionPeriod.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release frees key without storing a response, so the request can be retried. A key no
+// longer held by claim is left alone.
+func (s *IdempotencyService) Release(ctx context.Context, key, claim string) error {
+	return releaseScript.Run(ctx, s.client, []string{keyPrefix + key}, claim).Err()
+}
+
+func (s *IdempotencyService) get(ctx context.Context, key string) (*Record, error) {
+	data, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
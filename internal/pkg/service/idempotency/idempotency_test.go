@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestService(t *testing.T) (*IdempotencyService, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewIdempotencyService(client), server
+}
+
+func TestBeginReplaysCompletedResponse(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	claim, existing, err := service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || claim == "" || existing != nil {
+		t.Fatalf("first Begin: got %q, %v, %v", claim, existing, err)
+	}
+
+	_, existing, err = service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || existing == nil || existing.Status != StatusInProgress {
+		t.Fatalf("Begin while in progress: got %v, %v", existing, err)
+	}
+
+	record := Record{Fingerprint: "fp", StatusCode: http.StatusCreated, Body: []byte(`{"id":1}`)}
+	if err := service.Complete(ctx, "u1:k1", claim, record); err != nil {
+		t.Fatal(err)
+	}
+
+	_, existing, err = service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || existing == nil {
+		t.Fatalf("Begin after Complete: got %v, %v", existing, err)
+	}
+	if existing.Status != StatusCompleted || existing.StatusCode != http.StatusCreated || string(existing.Body) != `{"id":1}` || existing.Claim != "" {
+		t.Fatalf("stored record: got %+v", existing)
+	}
+}
+
+func TestReleaseFreesKeyForRetry(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	claim, _, err := service.Begin(ctx, "u1:k1", "fp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Release(ctx, "u1:k1", claim); err != nil {
+		t.Fatal(err)
+	}
+
+	retryClaim, existing, err := service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || retryClaim == "" || existing != nil {
+		t.Fatalf("Begin after Release: got %q, %v, %v", retryClaim, existing, err)
+	}
+}
+
+func TestExpiredClaimCannotTouchRetrysKey(t *testing.T) {
+	ctx := context.Background()
+	service, server := newTestService(t)
+
+	staleClaim, _, err := service.Begin(ctx, "u1:k1", "fp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(lockTimeout + 1)
+
+	retryClaim, existing, err := service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || retryClaim == "" || existing != nil {
+		t.Fatalf("Begin after the claim expired: got %q, %v, %v", retryClaim, existing, err)
+	}
+
+	if err := service.Release(ctx, "u1:k1", staleClaim); err != nil {
+		t.Fatal(err)
+	}
+	err = service.Complete(ctx, "u1:k1", staleClaim, Record{Fingerprint: "fp", StatusCode: http.StatusOK})
+	if !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("Complete with the expired claim: got %v, want ErrClaimLost", err)
+	}
+
+	_, existing, err = service.Begin(ctx, "u1:k1", "fp")
+	if err != nil || existing == nil || existing.Status != StatusInProgress || existing.Claim != retryClaim {
+		t.Fatalf("the retry's claim was not kept: got %+v, %v", existing, err)
+	}
+
+	if err := service.Complete(ctx, "u1:k1", retryClaim, Record{Fingerprint: "fp", StatusCode: http.StatusOK}); err != nil {
+		t.Fatalf("Complete with the retry's claim: %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/thanhpk/randstr"
+)
+
+const (
+	stateKeyPrefix = "oidc_state:"
+	StateExpiresAt = 10 * time.Minute
+)
+
+var (
+	ErrUnknownProvider = apperror.NotFound("identity_provider_not_found", "Identity provider not found")
+	ErrInvalidState    = apperror.Validation("invalid_login_state", "Invalid or expired login state")
+)
+
+// ProviderConfig configures an OpenID Connect provider. Endpoints are discovered from
+// the issuer unless set explicitly.
+type ProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+}
+
+// Claims is the verified identity returned by a provider.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+type loginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+// OIDCService runs the authorization code flow with PKCE against the configured providers.
+// Login state is kept in Redis so the callback can be served by any replica.
+type OIDCService struct {
+	client     *redis.Client
+	httpClient *http.Client
+	providers  map[string]*provider
+}
+
+type provider struct {
+	config ProviderConfig
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewOIDCService(client *redis.Client, configs []ProviderConfig) *OIDCService {
+	providers := make(map[string]*provider, len(configs))
+	for _, config := range configs {
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers[config.Name] = &provider{config: config}
+	}
+
+	return &OIDCService{
+		client:     client,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		providers:  providers,
+	}
+}
+
+// AuthURL starts a login and returns the provider URL the user should be redirected to,
+// and the state the provider will send back to the callback. The state must also be kept
+// in the browser that started the login, so that the callback can check the login is its own.
+func (s *OIDCService) AuthURL(ctx context.Context, providerName string) (authURL, state string, err error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	discovery, err := s.discover(ctx, p)
+	if err != nil {
+		return "", "", err
+	}
+
+	state = randstr.Hex(16)
+	loginState := loginState{
+		Provider:     providerName,
+		Nonce:        randstr.Hex(16),
+		CodeVerifier: randstr.Base62(64),
+	}
+
+	stateJSON, err := json.Marshal(loginState)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.client.Set(ctx, stateKeyPrefix+state, stateJSON, StateExpiresAt).Err(); err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(loginState.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {loginState.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), state, nil
+}
+
+// Exchange completes a login: it consumes the state, redeems the code and verifies the ID token.
+func (s *OIDCService) Exchange(ctx context.Context, providerName, state, code string) (*Claims, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	stateJSON, err := s.client.GetDel(ctx, stateKeyPrefix+state).Result()
+	if err == redis.Nil {
+		return nil, ErrInvalidState
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var loginState loginState
+	if err := json.Unmarshal([]byte(stateJSON), &loginState); err != nil {
+		return nil, err
+	}
+
+	if loginState.Provider != providerName {
+		return nil, ErrInvalidState
+	}
+
+	discovery, err := s.discover(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := s.redeemCode(ctx, p, discovery, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verifyIDToken(ctx, p, discovery, rawIDToken, loginState.Nonce)
+}
+
+func (s *OIDCService) redeemCode(ctx context.Context, p *provider, discovery *discoveryDocument, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("could not decode token response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK || tokenResponse.Error != "" {
+		return "", fmt.Errorf("token exchange failed: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	if tokenResponse.IDToken == "" {
+		return "", errors.New("token response did not include an id_token")
+	}
+
+	return tokenResponse.IDToken, nil
+}
+
+func (s *OIDCService) verifyIDToken(ctx context.Context, p *provider, discovery *discoveryDocument, rawIDToken, nonce string) (*Claims, error) {
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return s.publicKey(ctx, p, discovery, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid id_token claims")
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, errors.New("id_token issuer mismatch")
+	}
+
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, errors.New("id_token audience mismatch")
+	}
+
+	if claims["nonce"] != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	result := &Claims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.GivenName, _ = claims["given_name"].(string)
+	result.FamilyName, _ = claims["family_name"].(string)
+	result.Name, _ = claims["name"].(string)
+
+	// Some providers send email_verified as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+
+	if result.Subject == "" {
+		return nil, errors.New("id_token is missing the subject")
+	}
+
+	return result, nil
+}
+
+func (s *OIDCService) discover(ctx context.Context, p *provider) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := &discoveryDocument{
+		Issuer:                p.config.Issuer,
+		AuthorizationEndpoint: p.config.AuthURL,
+		TokenEndpoint:         p.config.TokenURL,
+		JWKSURI:               p.config.JWKSURL,
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+
+		var document discoveryDocument
+		if err := s.getJSON(ctx, discoveryURL, &document); err != nil {
+			return nil, fmt.Errorf("could not discover %s: %w", p.config.Name, err)
+		}
+
+		if document.Issuer != p.config.Issuer {
+			return nil, fmt.Errorf("discovered issuer %q does not match configured issuer %q", document.Issuer, p.config.Issuer)
+		}
+
+		if discovery.AuthorizationEndpoint == "" {
+			discovery.AuthorizationEndpoint = document.AuthorizationEndpoint
+		}
+		if discovery.TokenEndpoint == "" {
+			discovery.TokenEndpoint = document.TokenEndpoint
+		}
+		if discovery.JWKSURI == "" {
+			discovery.JWKSURI = document.JWKSURI
+		}
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// publicKey returns the provider key with the given ID, refetching the provider's key set
+// when the key is unknown so that provider-side rotation is picked up.
+func (s *OIDCService) publicKey(ctx context.Context, p *provider, discovery *discoveryDocument, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(p.keysAt) < time.Minute {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var keySet struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Curve   string `json:"crv"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := s.getJSON(ctx, discovery.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("could not fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		switch jwk.KeyType {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Curve {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[jwk.KeyID] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *OIDCService) getJSON(ctx context.Context, url string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", response.StatusCode, url)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
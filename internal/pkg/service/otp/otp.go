@@ -4,13 +4,51 @@ import (
 	"context"
 	"time"
 
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+
 	"github.com/redis/go-redis/v9"
 	"github.com/thanhpk/randstr"
 )
 
+var (
+	ErrInvalidOTP = apperror.Validation("invalid_otp", "Invalid or expired OTP")
+	// ErrTooManyOTPRequests is returned when too many codes have been sent to an address.
+	ErrTooManyOTPRequests = apperror.RateLimited("too_many_otp_requests", "Too many codes have been requested, please try again later")
+	// ErrTooManyOTPAttempts is returned once too many wrong codes have been tried for an
+	// address, and until the window they were counted in has passed.
+	ErrTooManyOTPAttempts = apperror.RateLimited("too_many_otp_attempts", "Too many incorrect codes, please try again later")
+)
+
+// Purpose binds a code to the flow it was issued for, so that a code sent to confirm an
+// email change cannot be used to reset a password or sign in, and vice versa.
+type Purpose string
+
+const (
+	PurposePasswordReset    Purpose = "password_reset"
+	PurposeEmailChange      Purpose = "email_change"
+	PurposeLogin            Purpose = "login"
+	PurposeAccountDeletion  Purpose = "account_deletion"
+	PurposeReauthentication Purpose = "reauthentication"
+)
+
+const (
+	otpExpiresAt = 15 * time.Minute
+
+	// maxAttempts is how many wrong guesses are allowed per address in attemptsWindow. The
+	// count is kept across codes, so that requesting a new code does not buy more guesses.
+	maxAttempts    = 5
+	attemptsWindow = time.Hour
+
+	// maxSends is how many codes may be sent to an address per purpose in sendsWindow.
+	maxSends    = 5
+	sendsWindow = time.Hour
+)
+
 type OTPService interface {
-	GenerateAndStoreOTP(email string) (string, error)
-	ValidateOTP(email, otp string) error
+	GenerateAndStoreOTP(ctx context.Context, purpose Purpose, email string) (string, error)
+	ValidateOTP(ctx context.Context, purpose Purpose, email, otp string) error
+	GenerateAndStoreLinkToken(ctx context.Context, purpose Purpose, email string) (string, error)
+	ConsumeLinkToken(ctx context.Context, purpose Purpose, token string) (string, error)
 }
 
 type otpService struct {
@@ -23,50 +61,121 @@ func NewOTPService(redisClient *redis.Client) OTPService {
 	}
 }
 
-func (s *otpService) set(key string, value interface{}, expiration time.Duration) error {
-	ctx := context.Background()
+func (s *otpService) set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return s.redisClient.Set(ctx, key, value, expiration).Err()
 }
 
-func (s *otpService) get(key string) (string, error) {
-	ctx := context.Background()
-	val, err := s.redisClient.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return "", nil
+func codeKey(purpose Purpose, email string) string {
+	return "otp_code:" + string(purpose) + ":" + email
+}
+
+func attemptsKey(purpose Purpose, email string) string {
+	return "otp_attempts:" + string(purpose) + ":" + email
+}
+
+func sendsKey(purpose Purpose, email string) string {
+	return "otp_sends:" + string(purpose) + ":" + email
+}
+
+// countInWindow increments the counter at key, which expires window after its first increment.
+func (s *otpService) countInWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := s.redisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
 	}
-	return val, err
+	return count.Val(), nil
 }
 
-func (s *otpService) del(key string) error {
-	ctx := context.Background()
-	return s.redisClient.Del(ctx, key).Err()
+func linkKey(purpose Purpose, token string) string {
+	return "otp_link:" + string(purpose) + ":" + token
 }
 
-func (s *otpService) GenerateAndStoreOTP(email string) (string, error) {
+// GenerateAndStoreOTP replaces the address's code for purpose with a new one. It returns
+// ErrTooManyOTPRequests once too many codes have been sent to the address.
+func (s *otpService) GenerateAndStoreOTP(ctx context.Context, purpose Purpose, email string) (string, error) {
+	sends, err := s.countInWindow(ctx, sendsKey(purpose, email), sendsWindow)
+	if err != nil {
+		return "", err
+	}
+
+	if sends > maxSends {
+		return "", ErrTooManyOTPRequests
+	}
+
 	otp := randstr.String(6, "0123456789")
 
-	otpExpiresAt := 15 * time.Minute
-	if err := s.set("otp_code:"+email, otp, otpExpiresAt); err != nil {
+	if err := s.set(ctx, codeKey(purpose, email), otp, otpExpiresAt); err != nil {
 		return "", err
 	}
 
 	return otp, nil
 }
 
-func (s *otpService) ValidateOTP(email, userOTP string) error {
-	key := "otp_code:" + email
-	storedOTP, err := s.get(key)
+// validateOTPScript checks a code and counts a miss in one step, so that parallel guesses
+// cannot all pass the attempt limit before any of them is counted. Six digits are easy to
+// guess without a limit, so once the address has used up its attempts every code is
+// refused, including one sent after the misses. Misses count even without a code, so that
+// the limit does not reveal which addresses have one.
+var validateOTPScript = redis.NewScript(`
+local attempts = tonumber(redis.call("GET", KEYS[2]) or "0")
+if attempts >= tonumber(ARGV[2]) then
+	redis.call("DEL", KEYS[1])
+	return -1
+end
+local code = redis.call("GET", KEYS[1])
+if not code or code ~= ARGV[1] then
+	redis.call("INCR", KEYS[2])
+	if redis.call("PTTL", KEYS[2]) < 0 then
+		redis.call("PEXPIRE", KEYS[2], ARGV[3])
+	end
+	return 0
+end
+redis.call("DEL", KEYS[1], KEYS[2])
+return 1
+`)
+
+func (s *otpService) ValidateOTP(ctx context.Context, purpose Purpose, email, userOTP string) error {
+	keys := []string{codeKey(purpose, email), attemptsKey(purpose, email)}
+	result, err := validateOTPScript.Run(ctx, s.redisClient, keys,
+		userOTP, maxAttempts, attemptsWindow.Milliseconds()).Int()
 	if err != nil {
 		return err
 	}
 
-	if storedOTP != userOTP {
-		return err
+	switch result {
+	case 1:
+		return nil
+	case -1:
+		return ErrTooManyOTPAttempts
+	default:
+		return ErrInvalidOTP
 	}
+}
 
-	if err := s.del(key); err != nil {
-		return err
+// GenerateAndStoreLinkToken returns a single-use token for embedding in an emailed link.
+// Unlike a code it is long enough to be unguessable, so it is looked up by the token alone.
+func (s *otpService) GenerateAndStoreLinkToken(ctx context.Context, purpose Purpose, email string) (string, error) {
+	token := randstr.Hex(32)
+
+	if err := s.set(ctx, linkKey(purpose, token), email, otpExpiresAt); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeLinkToken returns the email the token was issued to and invalidates the token.
+func (s *otpService) ConsumeLinkToken(ctx context.Context, purpose Purpose, token string) (string, error) {
+	email, err := s.redisClient.GetDel(ctx, linkKey(purpose, token)).Result()
+	if err == redis.Nil {
+		return "", ErrInvalidOTP
+	}
+
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	return email, nil
 }
@@ -0,0 +1,99 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestService(t *testing.T) OTPService {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewOTPService(client)
+}
+
+func TestValidateOTPAcceptsCodeOnce(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	code, err := service.GenerateAndStoreOTP(ctx, PurposeLogin, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ValidateOTP(ctx, PurposePasswordReset, "ada@example.com", code); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("code for another purpose: got %v, want ErrInvalidOTP", err)
+	}
+	if err := service.ValidateOTP(ctx, PurposeLogin, "ada@example.com", code); err != nil {
+		t.Fatalf("valid code: got %v", err)
+	}
+	if err := service.ValidateOTP(ctx, PurposeLogin, "ada@example.com", code); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("reused code: got %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestValidateOTPLimitsConcurrentGuesses(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	code, err := service.GenerateAndStoreOTP(ctx, PurposeLogin, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+
+	const guesses = 50
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- service.ValidateOTP(ctx, PurposeLogin, "ada@example.com", wrongCode)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var invalid, limited int
+	for err := range results {
+		switch {
+		case errors.Is(err, ErrInvalidOTP):
+			invalid++
+		case errors.Is(err, ErrTooManyOTPAttempts):
+			limited++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if invalid != maxAttempts || limited != guesses-maxAttempts {
+		t.Fatalf("got %d misses counted and %d limited, want %d and %d", invalid, limited, maxAttempts, guesses-maxAttempts)
+	}
+
+	if err := service.ValidateOTP(ctx, PurposeLogin, "ada@example.com", code); !errors.Is(err, ErrTooManyOTPAttempts) {
+		t.Fatalf("correct code after the limit: got %v, want ErrTooManyOTPAttempts", err)
+	}
+}
+
+func TestGenerateAndStoreOTPLimitsSends(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	for i := 0; i < maxSends; i++ {
+		if _, err := service.GenerateAndStoreOTP(ctx, PurposeLogin, "ada@example.com"); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+	}
+	if _, err := service.GenerateAndStoreOTP(ctx, PurposeLogin, "ada@example.com"); !errors.Is(err, ErrTooManyOTPRequests) {
+		t.Fatalf("send over the limit: got %v, want ErrTooManyOTPRequests", err)
+	}
+}
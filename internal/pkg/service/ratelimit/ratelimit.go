@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "rate_limit:"
+
+// RateLimitService counts requests in fixed windows, shared by every replica.
+type RateLimitService struct {
+	client *redis.Client
+}
+
+func NewRateLimitService(client *redis.Client) *RateLimitService {
+	return &RateLimitService{client: client}
+}
+
+// Allow counts a request against key and reports whether it is within limit for the
+// current window. When it is not, retryAfter is how long until the window ends.
+func (s *RateLimitService) Allow(ctx context.Context, key string, limit int64, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	pipe := s.client.TxPipeline()
+	count := pipe.Incr(ctx, keyPrefix+key)
+	pipe.ExpireNX(ctx, keyPrefix+key, window)
+	ttl := pipe.PTTL(ctx, keyPrefix+key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, 0, err
+	}
+
+	if count.Val() <= limit {
+		return true, 0, nil
+	}
+	return false, ttl.Val(), nil
+}
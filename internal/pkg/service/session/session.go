@@ -8,9 +8,29 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	sessionKeyPrefix      = "session:"
+	userSessionsKeyPrefix = "user_sessions:"
+)
+
+// SessionData is a login session. Its ID is stable for the lifetime of the login and
+// identifies the refresh-token family; RefreshTokenID is the jti of the only refresh
+// token in that family that may still be exchanged.
 type SessionData struct {
-	UserID string `json:"userID"`
-	Email  string `json:"email"`
+	ID             string    `json:"id"`
+	UserID         string    `json:"userID"`
+	Email          string    `json:"email"`
+	RefreshTokenID string    `json:"refreshTokenID"`
+	UserAgent      string    `json:"userAgent"`
+	IPAddress      string    `json:"ipAddress"`
+	CreatedAt      time.Time `json:"createdAt"`
+	LastUsedAt     time.Time `json:"lastUsedAt"`
+}
+
+// ClientInfo describes the device a session was created or last used from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
 
 type SessionService struct {
@@ -21,22 +41,63 @@ func NewSessionService(client *redis.Client) *SessionService {
 	return &SessionService{client: client}
 }
 
-func (s *SessionService) SetSession(ctx context.Context, sessionID, userID, email string, expiresAt time.Duration) error {
-	sessionData := SessionData{
-		UserID: userID,
-		Email:  email,
-	}
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
 
+// SetSession stores the session and indexes it under its owner so that all of
+// a user's sessions can be listed or revoked together.
+func (s *SessionService) SetSession(ctx context.Context, sessionData SessionData, expiresAt time.Duration) error {
 	sessionJSON, err := json.Marshal(sessionData)
 	if err != nil {
 		return err
 	}
 
-	return s.client.Set(ctx, sessionID, sessionJSON, expiresAt).Err()
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, sessionKey(sessionData.ID), sessionJSON, expiresAt)
+	pipe.SAdd(ctx, userSessionsKey(sessionData.UserID), sessionData.ID)
+	pipe.Expire(ctx, userSessionsKey(sessionData.UserID), expiresAt)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// rotateSessionScript replaces a session only while its refresh token is still the one
+// being exchanged, so that of two concurrent exchanges of the same token only one wins.
+var rotateSessionScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current or cjson.decode(current)["refreshTokenID"] ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+redis.call("SADD", KEYS[2], ARGV[4])
+redis.call("PEXPIRE", KEYS[2], ARGV[3])
+return 1
+`)
+
+// RotateSession stores the session if its stored refresh token is still
+// previousRefreshTokenID. It reports false when the session has expired or another
+// exchange has already rotated it.
+func (s *SessionService) RotateSession(ctx context.Context, sessionData SessionData, previousRefreshTokenID string, expiresAt time.Duration) (bool, error) {
+	sessionJSON, err := json.Marshal(sessionData)
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{sessionKey(sessionData.ID), userSessionsKey(sessionData.UserID)}
+	rotated, err := rotateSessionScript.Run(ctx, s.client, keys,
+		previousRefreshTokenID, sessionJSON, expiresAt.Milliseconds(), sessionData.ID).Int()
+	if err != nil {
+		return false, err
+	}
+	return rotated == 1, nil
 }
 
 func (s *SessionService) GetSession(ctx context.Context, sessionID string) (*SessionData, error) {
-	sessionJSON, err := s.client.Get(ctx, sessionID).Result()
+	sessionJSON, err := s.client.Get(ctx, sessionKey(sessionID)).Result()
 	if err == redis.Nil {
 		return nil, nil
 	}
@@ -54,5 +115,91 @@ func (s *SessionService) GetSession(ctx context.Context, sessionID string) (*Ses
 }
 
 func (s *SessionService) DeleteSession(ctx context.Context, sessionID string) error {
-	return s.client.Del(ctx, sessionID).Err()
+	sessionData, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if sessionData == nil {
+		return nil
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, sessionKey(sessionID))
+	pipe.SRem(ctx, userSessionsKey(sessionData.UserID), sessionID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ListUserSessions returns the user's active sessions, pruning index entries
+// whose session has already expired.
+func (s *SessionService) ListUserSessions(ctx context.Context, userID string) ([]SessionData, error) {
+	sessionIDs, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionData, 0, len(sessionIDs))
+	var staleIDs []interface{}
+
+	for _, sessionID := range sessionIDs {
+		sessionData, err := s.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if sessionData == nil {
+			staleIDs = append(staleIDs, sessionID)
+			continue
+		}
+
+		sessions = append(sessions, *sessionData)
+	}
+
+	if len(staleIDs) > 0 {
+		if err := s.client.SRem(ctx, userSessionsKey(userID), staleIDs...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// DeleteUserSession revokes a single session, but only if it belongs to the given user.
+// It reports whether a matching session was found.
+func (s *SessionService) DeleteUserSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	sessionData, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if sessionData == nil || sessionData.UserID != userID {
+		return false, nil
+	}
+
+	if err := s.DeleteSession(ctx, sessionID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteAllUserSessions revokes every session belonging to the user and returns their IDs.
+func (s *SessionService) DeleteAllUserSessions(ctx context.Context, userID string) ([]string, error) {
+	sessionIDs, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, sessionKey(sessionID))
+	}
+	keys = append(keys, userSessionsKey(userID))
+
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return nil, err
+	}
+
+	return sessionIDs, nil
 }
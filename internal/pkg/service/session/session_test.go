@@ -0,0 +1,104 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestService(t *testing.T) (*SessionService, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewSessionService(client), server
+}
+
+func TestRotateSessionAcceptsOnlyCurrentRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	session := SessionData{ID: "s1", UserID: "u1", RefreshTokenID: "r1"}
+	if err := service.SetSession(ctx, session, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	session.RefreshTokenID = "r2"
+	rotated, err := service.RotateSession(ctx, session, "r1", time.Hour)
+	if err != nil || !rotated {
+		t.Fatalf("rotating the current token: got %v, %v", rotated, err)
+	}
+
+	session.RefreshTokenID = "r3"
+	rotated, err = service.RotateSession(ctx, session, "r1", time.Hour)
+	if err != nil || rotated {
+		t.Fatalf("rotating a replaced token: got %v, %v", rotated, err)
+	}
+
+	stored, err := service.GetSession(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RefreshTokenID != "r2" {
+		t.Fatalf("stored refresh token: got %q, want r2", stored.RefreshTokenID)
+	}
+}
+
+func TestRotateSessionLetsOneConcurrentExchangeWin(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	if err := service.SetSession(ctx, SessionData{ID: "s1", UserID: "u1", RefreshTokenID: "r1"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	const exchanges = 20
+	results := make(chan bool, exchanges)
+	var wg sync.WaitGroup
+	for i := 0; i < exchanges; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			session := SessionData{ID: "s1", UserID: "u1", RefreshTokenID: string(rune('a' + i))}
+			rotated, err := service.RotateSession(ctx, session, "r1", time.Hour)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- rotated
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	winners := 0
+	for rotated := range results {
+		if rotated {
+			winners++
+		}
+	}
+	if winners != 1 {
+		t.Fatalf("got %d successful rotations of the same token, want 1", winners)
+	}
+}
+
+func TestRotateSessionRejectsExpiredSession(t *testing.T) {
+	ctx := context.Background()
+	service, server := newTestService(t)
+
+	if err := service.SetSession(ctx, SessionData{ID: "s1", UserID: "u1", RefreshTokenID: "r1"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	server.FastForward(2 * time.Minute)
+
+	rotated, err := service.RotateSession(ctx, SessionData{ID: "s1", UserID: "u1", RefreshTokenID: "r2"}, "r1", time.Hour)
+	if err != nil || rotated {
+		t.Fatalf("rotating an expired session: got %v, %v", rotated, err)
+	}
+	if stored, err := service.GetSession(ctx, "s1"); err != nil || stored != nil {
+		t.Fatalf("expired session was recreated: got %v, %v", stored, err)
+	}
+}
@@ -1,13 +1,18 @@
 package httpexception
 
 import (
+	"errors"
 	"net/http"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
 )
 
 type HTTPException struct {
 	StatusCode int
-	Message    string
-	Errors     interface{}
+	// Code is a stable machine-readable identifier of the error, unlike Message.
+	Code    string
+	Message string
+	Errors  interface{}
 }
 
 func (e *HTTPException) Error() string {
@@ -18,6 +23,7 @@ func (e *HTTPException) ToResponse() map[string]interface{} {
 	response := map[string]interface{}{
 		"success":    false,
 		"statusCode": e.StatusCode,
+		"code":       e.Code,
 		"message":    e.Message,
 	}
 
@@ -28,9 +34,50 @@ func (e *HTTPException) ToResponse() map[string]interface{} {
 	return response
 }
 
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindValidation:   http.StatusBadRequest,
+	apperror.KindUnavailable:  http.StatusServiceUnavailable,
+
+	apperror.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperror.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperror.KindRateLimited:          http.StatusTooManyRequests,
+}
+
+// FromError maps an error returned by a service to the response for it. Errors that are
+// not application errors become a generic internal server error, so their details never
+// reach the client.
+func FromError(err error) *HTTPException {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		statusCode, ok := statusByKind[appErr.Kind]
+		if !ok {
+			statusCode = http.StatusInternalServerError
+		}
+
+		return &HTTPException{
+			StatusCode: statusCode,
+			Code:       appErr.Code,
+			Message:    appErr.Message,
+			Errors:     appErr.Details,
+		}
+	}
+
+	var httpErr *HTTPException
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return NewInternalServerException(nil)
+}
+
 func NewBadRequestException(message string, err interface{}) *HTTPException {
 	return &HTTPException{
 		StatusCode: http.StatusBadRequest,
+		Code:       "bad_request",
 		Message:    message,
 		Errors:     err,
 	}
@@ -39,6 +86,7 @@ func NewBadRequestException(message string, err interface{}) *HTTPException {
 func NewConflictException(message string, err interface{}) *HTTPException {
 	return &HTTPException{
 		StatusCode: http.StatusConflict,
+		Code:       "conflict",
 		Message:    message,
 		Errors:     err,
 	}
@@ -47,6 +95,7 @@ func NewConflictException(message string, err interface{}) *HTTPException {
 func NewNotFoundException(message string, err interface{}) *HTTPException {
 	return &HTTPException{
 		StatusCode: http.StatusNotFound,
+		Code:       "not_found",
 		Message:    message,
 		Errors:     err,
 	}
@@ -55,6 +104,16 @@ func NewNotFoundException(message string, err interface{}) *HTTPException {
 func NewUnauthorizedException(message string, err interface{}) *HTTPException {
 	return &HTTPException{
 		StatusCode: http.StatusUnauthorized,
+		Code:       "unauthorized",
+		Message:    message,
+		Errors:     err,
+	}
+}
+
+func NewForbiddenException(message string, err interface{}) *HTTPException {
+	return &HTTPException{
+		StatusCode: http.StatusForbidden,
+		Code:       "forbidden",
 		Message:    message,
 		Errors:     err,
 	}
@@ -63,6 +122,7 @@ func NewUnauthorizedException(message string, err interface{}) *HTTPException {
 func NewInternalServerException(err interface{}) *HTTPException {
 	return &HTTPException{
 		StatusCode: http.StatusInternalServerError,
+		Code:       "internal_error",
 		Message:    "An unexpected error occurred...",
 		Errors:     err,
 	}
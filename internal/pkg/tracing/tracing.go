@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/buildinfo"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies this service in traces.
+const ServiceName = "event-booking-api"
+
+const instrumentationName = "github.com/edwinedjokpa/event-booking-api"
+
+type Options struct {
+	// Exporter is "otlp", "stdout", "file" or "none".
+	Exporter string
+	// File is where spans are appended when Exporter is "file".
+	File string
+	// SampleRatio is the fraction of new traces that are recorded.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator. The
+// returned function flushes buffered spans and must be called before the process exits.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	// Propagate incoming trace context even when this instance does not export spans, so
+	// a trace is not broken by passing through it.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if options.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeExporter, err := newExporter(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(ServiceName),
+			semconv.ServiceVersion(buildinfo.Get().Version),
+		),
+		// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME override the defaults above.
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		closeExporter()
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		defer closeExporter()
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+func newExporter(ctx context.Context, options Options) (sdktrace.SpanExporter, func(), error) {
+	switch options.Exporter {
+	case "otlp":
+		exporter, err := otlptracehttp.New(ctx)
+		return exporter, func() {}, err
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, func() {}, err
+	case "file":
+		file, err := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, func() { file.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", options.Exporter)
+	}
+}
+
+// Tracer returns the tracer for spans created by this service's own code.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
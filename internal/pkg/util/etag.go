@@ -0,0 +1,46 @@
+package util
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MatchesETag reports whether an If-Match header value matches etag. The header is "*" or
+// a list of entity tags, and is compared strongly, so weak tags never match.
+func MatchesETag(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (candidate == etag && !strings.HasPrefix(candidate, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotModified reports whether a GET request can be answered with 304 Not Modified,
+// because its If-None-Match or If-Modified-Since header shows that the client already has
+// the representation identified by etag and lastModified. If-None-Match takes precedence
+// and is compared weakly. A zero lastModified ignores If-Modified-Since.
+func IsNotModified(header http.Header, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have a resolution of one second.
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
@@ -1,12 +1,6 @@
 package util
 
 import (
-	"fmt"
-	"strings"
-
-	HTTPException "github.com/edwinedjokpa/event-booking-api/internal/pkg/shared/httpexception"
-
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -15,20 +9,6 @@ func GenerateUUID() string {
 	return uuid.New().String()
 }
 
-func FormatValidationErrors(err error) *HTTPException.HTTPException {
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		var errorMessages []string
-
-		for _, fieldErr := range validationErrors {
-			errorMessages = append(errorMessages, fmt.Sprintf("Field '%s' failed validation: %s", fieldErr.Field(), fieldErr.Tag()))
-		}
-
-		return HTTPException.NewBadRequestException("Validation failed", strings.Join(errorMessages, ", "))
-	}
-
-	return HTTPException.NewInternalServerException(err)
-}
-
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
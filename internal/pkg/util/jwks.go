@@ -0,0 +1,219 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKey is an asymmetric key used to sign tokens from ActiveFrom until the next
+// key in the rotation schedule becomes active.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	ActiveFrom time.Time
+}
+
+// KeySet holds the signing keys in rotation order, plus the legacy HS256 secret used
+// when no asymmetric keys are configured or while tokens signed with it are phased out.
+// The secret is superseded like a key by the first asymmetric key, so HS256 tokens stop
+// verifying once that key has been active for the grace period.
+type KeySet struct {
+	keys        []SigningKey
+	hmacSecret  []byte
+	gracePeriod time.Duration
+}
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewKeySet builds a key set. A key stops signing once its successor becomes active, but
+// stays valid for verification for gracePeriod afterwards so tokens it already signed keep working.
+// Keys without an activation time become active now, so the tokens they supersede, HS256
+// tokens included, keep working for the grace period from startup. Without a JWT secret,
+// one of the keys must already be active to sign tokens with.
+func NewKeySet(keys []SigningKey, hmacSecret string, gracePeriod time.Duration) (*KeySet, error) {
+	if len(keys) == 0 && hmacSecret == "" {
+		return nil, errors.New("no signing keys or JWT secret configured")
+	}
+
+	now := time.Now()
+	sorted := make([]SigningKey, len(keys))
+	copy(sorted, keys)
+	for i := range sorted {
+		if sorted[i].ActiveFrom.IsZero() {
+			sorted[i].ActiveFrom = now
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+
+	seen := make(map[string]bool, len(sorted))
+	for _, key := range sorted {
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	keySet := &KeySet{keys: sorted, hmacSecret: []byte(hmacSecret), gracePeriod: gracePeriod}
+	if hmacSecret == "" && keySet.signingKey(now) == nil {
+		return nil, errors.New("no signing key is active yet and no JWT secret is configured")
+	}
+	return keySet, nil
+}
+
+// LoadSigningKeys parses a rotation schedule of the form
+// "kid=/path/to/key.pem@2026-01-01T00:00:00Z,kid2=/path/to/key2.pem@2026-04-01T00:00:00Z".
+// Keys may be RSA (signed with RS256) or Ed25519 (signed with EdDSA) private keys in PEM form.
+// The activation time may be omitted, in which case the key is active from startup. Give
+// the first key an activation time to retire HS256 tokens at a fixed time instead.
+func LoadSigningKeys(schedule string) ([]SigningKey, error) {
+	var keys []SigningKey
+
+	for _, entry := range strings.Split(schedule, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, rest, found := strings.Cut(entry, "=")
+		if !found || kid == "" {
+			return nil, fmt.Errorf("invalid signing key entry %q", entry)
+		}
+
+		path, activeFromRaw, hasActiveFrom := strings.Cut(rest, "@")
+
+		var activeFrom time.Time
+		if hasActiveFrom {
+			parsed, err := time.Parse(time.RFC3339, activeFromRaw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid activation time for signing key %q: %w", kid, err)
+			}
+			activeFrom = parsed
+		}
+
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read signing key %q: %w", kid, err)
+		}
+
+		key := SigningKey{ID: kid, ActiveFrom: activeFrom}
+		if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+			key.Method = jwt.SigningMethodRS256
+			key.PrivateKey = rsaKey
+		} else if edKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+			key.Method = jwt.SigningMethodEdDSA
+			key.PrivateKey = edKey.(ed25519.PrivateKey)
+		} else {
+			return nil, fmt.Errorf("signing key %q is not an RSA or Ed25519 private key", kid)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// signingKey returns the most recently activated key, or nil if tokens should be HS256-signed.
+func (ks *KeySet) signingKey(now time.Time) *SigningKey {
+	var current *SigningKey
+	for i := range ks.keys {
+		if ks.keys[i].ActiveFrom.After(now) {
+			break
+		}
+		current = &ks.keys[i]
+	}
+	return current
+}
+
+// hmacSecretFor returns the JWT secret while it can still verify HS256 tokens, or nil once
+// the first asymmetric key has superseded it for longer than the grace period.
+func (ks *KeySet) hmacSecretFor(now time.Time) []byte {
+	if len(ks.keys) > 0 {
+		supersededAt := ks.keys[0].ActiveFrom
+		if !supersededAt.After(now) && now.Sub(supersededAt) > ks.gracePeriod {
+			return nil
+		}
+	}
+	return ks.hmacSecret
+}
+
+// verificationKey returns the key with the given ID if it is active, or was superseded
+// less than the grace period ago.
+func (ks *KeySet) verificationKey(kid string, now time.Time) *SigningKey {
+	for i := range ks.keys {
+		key := &ks.keys[i]
+		if key.ID != kid {
+			continue
+		}
+
+		if key.ActiveFrom.After(now) {
+			return nil
+		}
+
+		if i+1 < len(ks.keys) {
+			supersededAt := ks.keys[i+1].ActiveFrom
+			if !supersededAt.After(now) && now.Sub(supersededAt) > ks.gracePeriod {
+				return nil
+			}
+		}
+		return key
+	}
+	return nil
+}
+
+// JWKS returns the public keys other services should trust: those that can currently
+// verify a token, plus scheduled keys so verifiers can cache them before they are used.
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	now := time.Now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for i, key := range ks.keys {
+		if !key.ActiveFrom.After(now) && ks.verificationKey(key.ID, now) == nil {
+			continue
+		}
+
+		jwk := JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch publicKey := ks.keys[i].PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,101 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "legacy-secret"
+
+func newTestSigningKey(t *testing.T, id string, activeFrom time.Time) SigningKey {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey, ActiveFrom: activeFrom}
+}
+
+func newHS256Token(t *testing.T) string {
+	t.Helper()
+	legacyKeySet, err := NewKeySet(nil, testSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := GenerateToken(jwt.MapClaims{"id": "user-1"}, time.Hour, legacyKeySet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestHS256TokenVerifiesAfterSwitchingToKeyWithoutActivationTime(t *testing.T) {
+	token := newHS256Token(t)
+
+	keySet, err := NewKeySet([]SigningKey{newTestSigningKey(t, "k1", time.Time{})}, testSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ValidateToken(token, keySet); err != nil {
+		t.Fatalf("HS256 token right after the switch: %v", err)
+	}
+
+	newToken, err := GenerateToken(jwt.MapClaims{"id": "user-1"}, time.Hour, keySet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := ValidateToken(newToken, keySet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "k1" {
+		t.Fatalf("new tokens are signed with %v, want k1", parsed.Header["kid"])
+	}
+}
+
+func TestHS256TokenRejectedAfterGracePeriod(t *testing.T) {
+	token := newHS256Token(t)
+
+	activeFrom := time.Now().Add(-2 * time.Hour)
+	keySet, err := NewKeySet([]SigningKey{newTestSigningKey(t, "k1", activeFrom)}, testSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ValidateToken(token, keySet); err == nil {
+		t.Fatal("HS256 token verified after the grace period")
+	}
+}
+
+func TestRetiredKeyRejectedAfterGracePeriod(t *testing.T) {
+	oldKey := newTestSigningKey(t, "old", time.Now().Add(-3*time.Hour))
+	oldKeySet, err := NewKeySet([]SigningKey{oldKey}, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := GenerateToken(jwt.MapClaims{"id": "user-1"}, time.Hour, oldKeySet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := NewKeySet([]SigningKey{oldKey, newTestSigningKey(t, "new", time.Now().Add(-30*time.Minute))}, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ValidateToken(token, recent); err != nil {
+		t.Fatalf("token of a key superseded within the grace period: %v", err)
+	}
+
+	expired, err := NewKeySet([]SigningKey{oldKey, newTestSigningKey(t, "new", time.Now().Add(-2*time.Hour))}, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ValidateToken(token, expired); err == nil {
+		t.Fatal("token of a key superseded before the grace period verified")
+	}
+}
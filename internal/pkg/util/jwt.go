@@ -8,15 +8,33 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-func GenerateToken(claims jwt.MapClaims, expiresAt time.Duration, jwtSecret string) (string, error) {
+// GenerateToken signs the claims with the key set's active asymmetric key, identified by
+// the "kid" header, falling back to HS256 with the JWT secret when none is configured.
+func GenerateToken(claims jwt.MapClaims, expiresAt time.Duration, keySet *KeySet) (string, error) {
 	claims["iss"] = "My App"
 	claims["aud"] = "auth-service"
-	claims["exp"] = time.Now().Add(expiresAt).Unix()
-	claims["iat"] = time.Now().UTC().Unix()
+	now := time.Now()
+	claims["exp"] = now.Add(expiresAt).Unix()
+	// Issue times have millisecond precision so they can be ordered against user-wide
+	// revocations made in the same second.
+	claims["iat"] = float64(now.UnixMilli()) / 1000
+	if _, ok := claims["jti"]; !ok {
+		claims["jti"] = GenerateUUID()
+	}
+
+	signingKey := keySet.signingKey(time.Now())
+	if signingKey == nil {
+		if len(keySet.hmacSecret) == 0 {
+			return "", errors.New("no signing key is active and no JWT secret is configured")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(keySet.hmacSecret)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.ID
 
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+	signedToken, err := token.SignedString(signingKey.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -24,12 +42,26 @@ func GenerateToken(claims jwt.MapClaims, expiresAt time.Duration, jwtSecret stri
 	return signedToken, nil
 }
 
-func ValidateToken(tokenString string, secretKey []byte) (*jwt.Token, jwt.MapClaims, error) {
+func ValidateToken(tokenString string, keySet *KeySet) (*jwt.Token, jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			hmacSecret := keySet.hmacSecretFor(time.Now())
+			if len(hmacSecret) == 0 {
+				return nil, errors.New("HMAC-signed tokens are not accepted")
+			}
+			return hmacSecret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		signingKey := keySet.verificationKey(kid, time.Now())
+		if signingKey == nil {
+			return nil, fmt.Errorf("unknown or retired signing key: %v", token.Header["kid"])
+		}
+
+		if token.Method.Alg() != signingKey.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return secretKey, nil
+		return signingKey.PrivateKey.Public(), nil
 	})
 
 	if err != nil || !token.Valid {
@@ -43,3 +75,12 @@ func ValidateToken(tokenString string, secretKey []byte) (*jwt.Token, jwt.MapCla
 	return token, claims, nil
 
 }
+
+// TokenTTL returns how long a token with the given claims remains valid.
+func TokenTTL(claims jwt.MapClaims) time.Duration {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return 0
+	}
+	return time.Until(time.Unix(int64(exp), 0))
+}
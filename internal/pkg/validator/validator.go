@@ -1,16 +1,50 @@
 package validator
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/apperror"
+	"github.com/edwinedjokpa/event-booking-api/internal/pkg/i18n"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	ptTranslations "github.com/go-playground/validator/v10/translations/pt"
+)
+
+var (
+	ErrValidationFailed = apperror.Validation("validation_failed", "Validation failed")
+	ErrMalformedBody    = apperror.Validation("malformed_request_body", "Request body is not valid JSON")
 )
 
-func NewValidator() *validator.Validate {
-	validator := validator.New()
+// FieldError describes one failed rule on one field of a request body, so that clients
+// can show the message next to the matching form field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Validator validates request bodies and reports failures as FieldErrors with messages in
+// the request's locale.
+type Validator struct {
+	validate    *validator.Validate
+	translators *ut.UniversalTranslator
+}
+
+func NewValidator() (*Validator, error) {
+	validate := validator.New()
 
-	validator.RegisterTagNameFunc(func(fld reflect.StructField) string {
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
 			return ""
@@ -18,5 +52,140 @@ func NewValidator() *validator.Validate {
 		return name
 	})
 
-	return validator
+	english := en.New()
+	translators := ut.New(english, english, fr.New(), pt.New())
+	defaultTranslations := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"fr": frTranslations.RegisterDefaultTranslations,
+		"pt": ptTranslations.RegisterDefaultTranslations,
+	}
+
+	for _, locale := range i18n.Locales {
+		translator, _ := translators.GetTranslator(locale)
+		if err := defaultTranslations[locale](validate, translator); err != nil {
+			return nil, err
+		}
+
+		if err := registerCatalogueTranslations(validate, translator, locale); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Validator{validate, translators}, nil
+}
+
+// catalogueRules are the rules whose messages come from the i18n catalogue, because the
+// default translations miss them in at least one locale.
+var catalogueRules = []string{"timezone", "bcp47_language_tag", "e164", "required_with", "required_without"}
+
+func registerCatalogueTranslations(validate *validator.Validate, translator ut.Translator, locale string) error {
+	for _, rule := range catalogueRules {
+		register := func(translator ut.Translator) error {
+			return translator.Add(rule, i18n.Message(locale, "validation."+rule), true)
+		}
+		translate := func(translator ut.Translator, fieldErr validator.FieldError) string {
+			message, _ := translator.T(fieldErr.Tag(), fieldErr.Field())
+			return message
+		}
+		if err := validate.RegisterTranslation(rule, translator, register, translate); err != nil {
+			return err
+		}
+	}
+
+	// Messages for decode errors and for rules without a translation.
+	for _, key := range []string{"type", "invalid"} {
+		if err := translator.Add(key, i18n.Message(locale, "validation."+key), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Struct validates the request against its validate tags. It returns ErrValidationFailed
+// with a FieldError for each failed rule.
+func (v *Validator) Struct(ctx context.Context, request interface{}) error {
+	err := v.validate.StructCtx(ctx, request)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	translator := v.translator(ctx)
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fieldErrors = append(fieldErrors, toFieldError(translator, fieldErr))
+	}
+
+	return ErrValidationFailed.WithDetails(fieldErrors)
+}
+
+// DecodeError converts an error from decoding a JSON request body. A value of the wrong type
+// is reported as a FieldError like any other validation failure.
+func (v *Validator) DecodeError(ctx context.Context, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		param := jsonType(typeErr.Type)
+		return ErrValidationFailed.WithDetails([]FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   param,
+			Message: translate(v.translator(ctx), "type", typeErr.Field, param),
+		}})
+	}
+
+	return ErrMalformedBody.WithCause(err)
+}
+
+func toFieldError(translator ut.Translator, fieldErr validator.FieldError) FieldError {
+	// The namespace starts with the struct's name, which means nothing to the client.
+	field := fieldErr.Namespace()
+	if _, rest, found := strings.Cut(field, "."); found {
+		field = rest
+	}
+
+	message := fieldErr.Translate(translator)
+	if message == fieldErr.Error() {
+		// Translate falls back to the raw error when a rule has no translation.
+		message = translate(translator, "invalid", fieldErr.Field())
+	}
+
+	return FieldError{
+		Field:   field,
+		Rule:    fieldErr.Tag(),
+		Param:   fieldErr.Param(),
+		Message: message,
+	}
+}
+
+// translator returns the translator for the request's locale.
+func (v *Validator) translator(ctx context.Context) ut.Translator {
+	translator, _ := v.translators.GetTranslator(i18n.FromContext(ctx))
+	return translator
+}
+
+func translate(translator ut.Translator, key string, params ...string) string {
+	message, err := translator.T(key, params...)
+	if err != nil {
+		return key
+	}
+	return message
+}
+
+// jsonType names the JSON type that decodes into t.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
 }
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type RegisterUserRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+type LoginUserRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type PasswordlessLoginRequest struct {
+	Email string `json:"email"`
+}
+
+// PasswordlessVerifyRequest carries either the emailed code with its address, or the
+// token from the emailed sign-in link.
+type PasswordlessVerifyRequest struct {
+	Email string `json:"email,omitempty"`
+	OTP   string `json:"otp,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	OTP         string `json:"otp"`
+	NewPassword string `json:"new_password"`
+}
+
+// Session is one signed-in device of the user. Current marks the client's own session.
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+type tokenData struct {
+	Token string `json:"token"`
+}
+
+func (c *Client) Register(ctx context.Context, request RegisterUserRequest) error {
+	return c.call(ctx, http.MethodPost, "/auth/register", request, nil, false)
+}
+
+// Login signs in with an email and password. The client keeps the tokens and refreshes
+// them as they expire.
+func (c *Client) Login(ctx context.Context, request LoginUserRequest) error {
+	return c.signIn(ctx, "/auth/login", request)
+}
+
+// StartPasswordlessLogin emails a sign-in code and link to the address.
+func (c *Client) StartPasswordlessLogin(ctx context.Context, request PasswordlessLoginRequest) error {
+	return c.call(ctx, http.MethodPost, "/auth/passwordless/start", request, nil, false)
+}
+
+// PasswordlessLogin signs in with the emailed code or the token from the emailed link.
+func (c *Client) PasswordlessLogin(ctx context.Context, request PasswordlessVerifyRequest) error {
+	return c.signIn(ctx, "/auth/passwordless/verify", request)
+}
+
+func (c *Client) ForgotPassword(ctx context.Context, request ForgotPasswordRequest) error {
+	return c.call(ctx, http.MethodPost, "/auth/forgot-password", request, nil, false)
+}
+
+func (c *Client) ResetPassword(ctx context.Context, request ResetPasswordRequest) error {
+	return c.call(ctx, http.MethodPost, "/auth/reset-password", request, nil, false)
+}
+
+// RefreshToken exchanges the refresh token for new tokens. Requests refresh the tokens
+// themselves when the access token expires, so this is rarely needed.
+func (c *Client) RefreshToken(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.refresh(ctx)
+}
+
+// Logout ends the current session and forgets its tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	req, err := newRequest(http.MethodPost, "/auth/logout", nil, true)
+	if err != nil {
+		return err
+	}
+	accessToken, refreshToken := c.Tokens()
+	req.refreshToken = refreshToken
+
+	if _, err := c.send(ctx, req, accessToken, nil); err != nil {
+		return err
+	}
+	c.SetTokens("", "")
+	return nil
+}
+
+// LogoutAll ends every session of the signed-in user and forgets the tokens.
+func (c *Client) LogoutAll(ctx context.Context) error {
+	if err := c.call(ctx, http.MethodPost, "/auth/logout-all", nil, nil, true); err != nil {
+		return err
+	}
+	c.SetTokens("", "")
+	return nil
+}
+
+func (c *Client) GetSessions(ctx context.Context) ([]Session, error) {
+	var data struct {
+		Sessions []Session `json:"sessions"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/auth/sessions", nil, &data, true); err != nil {
+		return nil, err
+	}
+	return data.Sessions, nil
+}
+
+func (c *Client) RevokeSession(ctx context.Context, sessionID string) error {
+	return c.call(ctx, http.MethodDelete, "/auth/sessions/"+url.PathEscape(sessionID), nil, nil, true)
+}
+
+// signIn calls a login endpoint and keeps the access token it returns and the refresh
+// token it sets as a cookie.
+func (c *Client) signIn(ctx context.Context, path string, body interface{}) error {
+	req, err := newRequest(http.MethodPost, path, body, false)
+	if err != nil {
+		return err
+	}
+	return c.exchange(ctx, req)
+}
+
+// refresh exchanges the refresh token for new tokens. Callers must hold refreshMu.
+func (c *Client) refresh(ctx context.Context) error {
+	req, err := newRequest(http.MethodPost, "/auth/refresh", nil, false)
+	if err != nil {
+		return err
+	}
+	_, req.refreshToken = c.Tokens()
+	return c.exchange(ctx, req)
+}
+
+func (c *Client) exchange(ctx context.Context, req request) error {
+	var data tokenData
+	response, err := c.send(ctx, req, "", &data)
+	if err != nil {
+		return err
+	}
+
+	refreshToken := req.refreshToken
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == refreshTokenCookie {
+			refreshToken = cookie.Value
+		}
+	}
+
+	c.SetTokens(data.Token, refreshToken)
+	return nil
+}
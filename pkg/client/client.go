@@ -0,0 +1,332 @@
+// Package client is a typed Go client for the Event Booking API, for services that call it
+// instead of hand-rolling HTTP requests. Requests and responses are decoded into the
+// client's own types, which follow the API's JSON.
+//
+// A client signs in with Login or PasswordlessLogin and then refreshes its access token
+// with the refresh token whenever the API rejects it, or authenticates every request with
+// an API key. Idempotent requests are retried with backoff when the API is unavailable,
+// and so are requests that create events or invitations, which carry an idempotency key.
+// Failures reported by the API are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries    = 3
+	defaultRetryBackoff  = 200 * time.Millisecond
+	maxRetryBackoff      = 5 * time.Second
+	refreshTokenCookie   = "refresh_token"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+type Options struct {
+	// HTTPClient sends the requests; http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// APIKey authenticates every request instead of signing in, as "ebk_<prefix>_<secret>".
+	APIKey string
+	// AcceptLanguage is sent with every request so that messages come back in that language.
+	AcceptLanguage string
+	// MaxRetries is how many times an idempotent request is retried; 3 when zero and never
+	// when negative.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for each retry after it;
+	// 200ms when zero.
+	RetryBackoff time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	options    Options
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	// refreshMu lets one request refresh the tokens while the others wait for it, since the
+	// API rotates the refresh token on every use.
+	refreshMu sync.Mutex
+}
+
+// New returns a client for the API at baseURL, such as "https://events.example.com/api".
+func New(baseURL string, options Options) (*Client, error) {
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("client: base URL %q must start with http:// or https://", baseURL)
+	}
+
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+	if options.RetryBackoff == 0 {
+		options.RetryBackoff = defaultRetryBackoff
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+		options:    options,
+	}, nil
+}
+
+// Tokens returns the current access and refresh tokens, so that a session can be saved and
+// restored with SetTokens.
+func (c *Client) Tokens() (accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken, c.refreshToken
+}
+
+// SetTokens signs the client in with tokens from an earlier session.
+func (c *Client) SetTokens(accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken, c.refreshToken = accessToken, refreshToken
+}
+
+// request is one API call. Body is encoded once so that the call can be sent again.
+type request struct {
+	method string
+	path   string
+	body   []byte
+	// authenticated calls carry the API key or the access token.
+	authenticated bool
+	// refreshToken is sent as the refresh token cookie when set.
+	refreshToken string
+	// idempotencyKey makes a request that is not idempotent safe to retry.
+	idempotencyKey string
+	// ifMatch makes the request conditional on the current version of what it changes.
+	ifMatch string
+}
+
+func newRequest(method, path string, body interface{}, authenticated bool) (request, error) {
+	req := request{method: method, path: path, authenticated: authenticated}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return req, fmt.Errorf("client: encoding %s %s request: %w", method, path, err)
+		}
+		req.body = encoded
+	}
+	return req, nil
+}
+
+// call sends a request and decodes the data field of the response envelope into data,
+// which may be nil.
+func (c *Client) call(ctx context.Context, method, path string, body, data interface{}, authenticated bool) error {
+	req, err := newRequest(method, path, body, authenticated)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, data)
+}
+
+// callIdempotent is call for requests that create something, with a new idempotency key
+// so that they can be retried without creating it twice.
+func (c *Client) callIdempotent(ctx context.Context, method, path string, body, data interface{}) error {
+	req, err := newRequest(method, path, body, true)
+	if err != nil {
+		return err
+	}
+
+	req.idempotencyKey, err = newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, data)
+}
+
+// do sends req. When the access token is rejected it refreshes the tokens and sends req
+// once more.
+func (c *Client) do(ctx context.Context, req request, data interface{}) error {
+	accessToken, _ := c.Tokens()
+	_, err := c.send(ctx, req, accessToken, data)
+
+	if !c.canRefresh(req, accessToken, err) {
+		return err
+	}
+	if refreshErr := c.refreshAfter(ctx, accessToken); refreshErr != nil {
+		return err
+	}
+
+	accessToken, _ = c.Tokens()
+	_, err = c.send(ctx, req, accessToken, data)
+	return err
+}
+
+// canRefresh reports whether err is an access token rejected because it expired, or was
+// signed with a key that has since been rotated out.
+func (c *Client) canRefresh(req request, accessToken string, err error) bool {
+	_, refreshToken := c.Tokens()
+	return req.authenticated && c.options.APIKey == "" && accessToken != "" && refreshToken != "" &&
+		errors.Is(err, ErrInvalidToken)
+}
+
+// refreshAfter refreshes the tokens unless another request already replaced staleToken.
+func (c *Client) refreshAfter(ctx context.Context, staleToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if accessToken, _ := c.Tokens(); accessToken != staleToken {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// send sends a request, retrying idempotent requests while the API is unavailable, and
+// returns the final response once its body has been decoded.
+func (c *Client) send(ctx context.Context, req request, accessToken string, data interface{}) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := c.sendOnce(ctx, req, accessToken, data)
+		canRetry := isIdempotent(req.method) || req.idempotencyKey != ""
+		if attempt >= c.options.MaxRetries || !canRetry || !isRetryable(ctx, response, err) {
+			return response, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt, response))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, req request, accessToken string, data interface{}) (*http.Response, error) {
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, body)
+	if err != nil {
+		return nil, fmt.Errorf("client: building %s %s request: %w", req.method, req.path, err)
+	}
+
+	httpRequest.Header.Set("Accept", "application/json")
+	if req.body != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if c.options.AcceptLanguage != "" {
+		httpRequest.Header.Set("Accept-Language", c.options.AcceptLanguage)
+	}
+	if req.authenticated {
+		switch {
+		case c.options.APIKey != "":
+			httpRequest.Header.Set("Authorization", "ApiKey "+c.options.APIKey)
+		case accessToken != "":
+			httpRequest.Header.Set("Authorization", "Bearer "+accessToken)
+		}
+	}
+	if req.idempotencyKey != "" {
+		httpRequest.Header.Set(idempotencyKeyHeader, req.idempotencyKey)
+	}
+	if req.ifMatch != "" {
+		httpRequest.Header.Set("If-Match", req.ifMatch)
+	}
+	if req.refreshToken != "" {
+		httpRequest.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: req.refreshToken})
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("client: %s %s: %w", req.method, req.path, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response, fmt.Errorf("client: reading %s %s response: %w", req.method, req.path, err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return response, decodeError(response.StatusCode, responseBody)
+	}
+
+	if data == nil || len(responseBody) == 0 {
+		return response, nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(responseBody, &envelope); err != nil {
+		return response, fmt.Errorf("client: decoding %s %s response: %w", req.method, req.path, err)
+	}
+	if len(envelope.Data) == 0 {
+		return response, nil
+	}
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		return response, fmt.Errorf("client: decoding %s %s response: %w", req.method, req.path, err)
+	}
+	return response, nil
+}
+
+// isIdempotent reports whether sending a request with method twice has the same effect as
+// sending it once, so that it is safe to retry.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryable reports whether a failed attempt may succeed if it is sent again.
+func isRetryable(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if response == nil {
+		// The request never got a response, for example because the connection was refused.
+		return err != nil
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		// An earlier attempt with the same idempotency key is still running.
+		return errors.Is(err, ErrIdempotentRequestInProgress)
+	}
+}
+
+func newIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := cryptorand.Read(key); err != nil {
+		return "", fmt.Errorf("client: generating idempotency key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// backoff is the delay before retry number attempt+1: the Retry-After header when the API
+// sent one, or an exponential delay with jitter so that clients do not retry in step.
+func (c *Client) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxRetryBackoff)
+		}
+	}
+
+	delay := min(c.options.RetryBackoff<<attempt, maxRetryBackoff)
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Errors the client handles itself, or that callers are expected to handle. Any other
+// failure can be matched by its code with a new *Error, see Error.Is.
+var (
+	ErrInvalidToken                = &Error{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Message: "Invalid token"}
+	ErrValidationFailed            = &Error{StatusCode: http.StatusBadRequest, Code: "validation_failed", Message: "Validation failed"}
+	ErrEventModified               = &Error{StatusCode: http.StatusPreconditionFailed, Code: "event_modified", Message: "Event has been changed since it was retrieved"}
+	ErrIdempotentRequestInProgress = &Error{StatusCode: http.StatusConflict, Code: "idempotent_request_in_progress", Message: "A request with this Idempotency-Key is still being processed"}
+)
+
+// Error is a failure reported by the API, decoded from its error response.
+type Error struct {
+	StatusCode int `json:"statusCode"`
+	// Code is the stable machine-readable identifier of the error, such as "event_not_found".
+	Code string `json:"code"`
+	// Message is localized, see Options.AcceptLanguage.
+	Message string `json:"message"`
+	// Details is the errors field of the response, see FieldErrors.
+	Details json.RawMessage `json:"errors,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("client: %s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// Is matches errors with the same code, so that errors.Is(err, ErrEventModified) or
+// errors.Is(err, &Error{Code: "event_not_found"}) works against an error from the client.
+func (e *Error) Is(target error) bool {
+	targetErr, ok := target.(*Error)
+	return ok && targetErr.Code == e.Code
+}
+
+// FieldError describes one failed rule on one field of a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors returns the fields that failed validation when the error is
+// ErrValidationFailed, and nil otherwise.
+func (e *Error) FieldErrors() []FieldError {
+	if e.Code != ErrValidationFailed.Code || len(e.Details) == 0 {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	if err := json.Unmarshal(e.Details, &fieldErrors); err != nil {
+		return nil
+	}
+	return fieldErrors
+}
+
+// decodeError decodes an error response. Responses that do not come from the API, such as
+// a proxy's error page, keep the status code with its standard text.
+func decodeError(statusCode int, body []byte) error {
+	apiErr := &Error{}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == "" {
+		return &Error{StatusCode: statusCode, Code: "unexpected_response", Message: http.StatusText(statusCode)}
+	}
+
+	apiErr.StatusCode = statusCode
+	return apiErr
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Event struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Location    string     `json:"location"`
+	Date        time.Time  `json:"date"`
+	UserID      string     `json:"user_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// Version is incremented by every update, see UpdateEvent.
+	Version int64 `json:"version"`
+}
+
+// MemberRole is one of "co_owner", "editor", "checkin_staff" or "viewer".
+type MemberRole string
+
+const (
+	MemberRoleCoOwner      MemberRole = "co_owner"
+	MemberRoleEditor       MemberRole = "editor"
+	MemberRoleCheckInStaff MemberRole = "checkin_staff"
+	MemberRoleViewer       MemberRole = "viewer"
+)
+
+// MemberStatus is one of "pending", "accepted" or "declined".
+type MemberStatus string
+
+const (
+	MemberStatusPending  MemberStatus = "pending"
+	MemberStatusAccepted MemberStatus = "accepted"
+	MemberStatusDeclined MemberStatus = "declined"
+)
+
+// EventMember is a user other than the owner invited to an event in a role.
+type EventMember struct {
+	ID        string       `json:"id"`
+	EventID   string       `json:"event_id"`
+	Email     string       `json:"email"`
+	UserID    *string      `json:"user_id,omitempty"`
+	Role      MemberRole   `json:"role"`
+	Status    MemberStatus `json:"status"`
+	InvitedBy string       `json:"invited_by"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type CreateEventRequest struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	Date        time.Time `json:"date"`
+}
+
+// UpdateEventRequest changes the fields that are not nil.
+type UpdateEventRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+}
+
+type InviteMemberRequest struct {
+	Email string     `json:"email"`
+	Role  MemberRole `json:"role"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role MemberRole `json:"role"`
+}
+
+type TransferOwnershipRequest struct {
+	MemberID string `json:"member_id"`
+}
+
+func (c *Client) GetAllEvents(ctx context.Context) ([]Event, error) {
+	var data struct {
+		Events []Event `json:"events"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/events", nil, &data, false); err != nil {
+		return nil, err
+	}
+	return data.Events, nil
+}
+
+func (c *Client) GetEventByID(ctx context.Context, eventID string) (*Event, error) {
+	var data struct {
+		Event Event `json:"event"`
+	}
+	if err := c.call(ctx, http.MethodGet, eventPath(eventID), nil, &data, false); err != nil {
+		return nil, err
+	}
+	return &data.Event, nil
+}
+
+func (c *Client) CreateEvent(ctx context.Context, request CreateEventRequest) error {
+	return c.callIdempotent(ctx, http.MethodPost, "/events/", request, nil)
+}
+
+// UpdateEvent updates the event if it is still at version, the Version of the event the
+// changes are based on. It fails with ErrEventModified when someone else has updated the
+// event since.
+func (c *Client) UpdateEvent(ctx context.Context, eventID string, version int64, request UpdateEventRequest) error {
+	req, err := newRequest(http.MethodPut, eventPath(eventID), request, true)
+	if err != nil {
+		return err
+	}
+
+	req.ifMatch = strconv.Quote(strconv.FormatInt(version, 10))
+	return c.do(ctx, req, nil)
+}
+
+func (c *Client) DeleteEvent(ctx context.Context, eventID string) error {
+	return c.call(ctx, http.MethodDelete, eventPath(eventID), nil, nil, true)
+}
+
+func (c *Client) GetEventMembers(ctx context.Context, eventID string) ([]EventMember, error) {
+	var data struct {
+		Members []EventMember `json:"members"`
+	}
+	if err := c.call(ctx, http.MethodGet, eventPath(eventID)+"/members", nil, &data, true); err != nil {
+		return nil, err
+	}
+	return data.Members, nil
+}
+
+// InviteMember invites a user by email to join the event in a role. They gain access once
+// they accept.
+func (c *Client) InviteMember(ctx context.Context, eventID string, request InviteMemberRequest) error {
+	return c.callIdempotent(ctx, http.MethodPost, eventPath(eventID)+"/members", request, nil)
+}
+
+func (c *Client) UpdateMemberRole(ctx context.Context, eventID, memberID string, request UpdateMemberRoleRequest) error {
+	return c.call(ctx, http.MethodPatch, memberPath(eventID, memberID), request, nil, true)
+}
+
+func (c *Client) RemoveMember(ctx context.Context, eventID, memberID string) error {
+	return c.call(ctx, http.MethodDelete, memberPath(eventID, memberID), nil, nil, true)
+}
+
+func (c *Client) TransferOwnership(ctx context.Context, eventID string, request TransferOwnershipRequest) error {
+	return c.call(ctx, http.MethodPost, eventPath(eventID)+"/transfer", request, nil, true)
+}
+
+// GetInvitations returns the signed-in user's pending invitations.
+func (c *Client) GetInvitations(ctx context.Context) ([]EventMember, error) {
+	var data struct {
+		Invitations []EventMember `json:"invitations"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/events/invitations", nil, &data, true); err != nil {
+		return nil, err
+	}
+	return data.Invitations, nil
+}
+
+func (c *Client) AcceptInvitation(ctx context.Context, invitationID string) error {
+	return c.call(ctx, http.MethodPost, invitationPath(invitationID)+"/accept", nil, nil, true)
+}
+
+func (c *Client) DeclineInvitation(ctx context.Context, invitationID string) error {
+	return c.call(ctx, http.MethodPost, invitationPath(invitationID)+"/decline", nil, nil, true)
+}
+
+func eventPath(eventID string) string {
+	return "/events/" + url.PathEscape(eventID)
+}
+
+func memberPath(eventID, memberID string) string {
+	return eventPath(eventID) + "/members/" + url.PathEscape(memberID)
+}
+
+func invitationPath(invitationID string) string {
+	return "/events/invitations/" + url.PathEscape(invitationID)
+}
@@ -1,11 +1,21 @@
 #!/bin/bash
 
-# Build the Go application
-go build -o event-booking-api ./cmd/api/
+# Build the Go application, stamping it with the version reported by /healthz
+BUILDINFO=github.com/edwinedjokpa/event-booking-api/internal/pkg/buildinfo
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git rev-parse HEAD 2>/dev/null)
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+
+go build \
+  -ldflags "-X $BUILDINFO.Version=$VERSION -X $BUILDINFO.Commit=$COMMIT -X $BUILDINFO.BuildTime=$BUILD_TIME" \
+  -o event-booking-api ./cmd/api/
 
 # Check if the build was successful
 if [ $? -eq 0 ]; then
-  echo "Build successful. Starting API..."
+  echo "Build successful. Applying database migrations..."
+  ./event-booking-api migrate up || exit 1
+
+  echo "Starting API..."
   # Run the executable
   ./event-booking-api
 else
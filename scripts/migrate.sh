@@ -0,0 +1,4 @@
+#!/bin/bash
+
+# Apply, revert or inspect database migrations, e.g. ./scripts/migrate.sh status
+go run ./cmd/api/ migrate "${@:-up}"
@@ -1,5 +1,8 @@
 #!/bin/bash
 
+echo "Applying database migrations..."
+go run ./cmd/api/ migrate up || exit 1
+
 echo "Starting API..."
 # Run the Go application directly
 go run ./cmd/api/
\ No newline at end of file